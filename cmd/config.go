@@ -0,0 +1,352 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+// Package cmd holds the implementations of gcs's non-GUI subcommands
+// (config, schema, serve), each invoked directly from main.go.
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/richardwilkes/gcs/v5/model/fxp"
+	"github.com/richardwilkes/gcs/v5/model/settings"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+var fxpIntType = reflect.TypeOf(fxp.Int(0))
+
+// ConfigEntry describes a single leaf setting discovered by walking
+// settings.Settings via reflection.
+type ConfigEntry struct {
+	Subsystem string `json:"subsystem"`
+	Path      string `json:"path"`
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Default   string `json:"default"`
+}
+
+// RunConfig implements the "gcs config" subcommand tree: info/get/set/reset.
+func RunConfig(args []string) error {
+	if len(args) == 0 {
+		return errs.New(i18n.Text("usage: gcs config <info|get|set|reset> [args...]"))
+	}
+	switch args[0] {
+	case "info":
+		return configInfo(args[1:])
+	case "get":
+		return configGet(args[1:])
+	case "set":
+		return configSet(args[1:])
+	case "reset":
+		return configReset(args[1:])
+	default:
+		return errs.Newf(i18n.Text("unknown config subcommand: %s"), args[0])
+	}
+}
+
+func configInfo(args []string) error {
+	fs := flag.NewFlagSet("config info", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, i18n.Text("emit JSON instead of a grouped text tree"))
+	if err := fs.Parse(args); err != nil {
+		return errs.Wrap(err)
+	}
+	entries := walkSettings(settings.Global())
+	if *asJSON {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	bySubsystem := make(map[string][]ConfigEntry)
+	var order []string
+	for _, e := range entries {
+		if _, exists := bySubsystem[e.Subsystem]; !exists {
+			order = append(order, e.Subsystem)
+		}
+		bySubsystem[e.Subsystem] = append(bySubsystem[e.Subsystem], e)
+	}
+	sort.Strings(order)
+	for _, subsystem := range order {
+		fmt.Println(subsystem + ":")
+		for _, e := range bySubsystem[subsystem] {
+			fmt.Printf("  %-40s %-10s = %-20s (default: %s)\n", e.Path, e.Type, e.Value, e.Default)
+		}
+	}
+	return nil
+}
+
+func configGet(args []string) error {
+	fs := flag.NewFlagSet("config get", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, i18n.Text("emit JSON instead of plain text"))
+	if err := fs.Parse(args); err != nil {
+		return errs.Wrap(err)
+	}
+	if fs.NArg() != 1 {
+		return errs.New(i18n.Text("usage: gcs config get [--json] <key>"))
+	}
+	key := fs.Arg(0)
+	for _, e := range walkSettings(settings.Global()) {
+		if e.Path == key {
+			if *asJSON {
+				data, err := json.Marshal(e)
+				if err != nil {
+					return errs.Wrap(err)
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			fmt.Println(e.Value)
+			return nil
+		}
+	}
+	return errs.Newf(i18n.Text("no such setting: %s"), key)
+}
+
+func configSet(args []string) error {
+	if len(args) != 2 {
+		return errs.New(i18n.Text("usage: gcs config set <key> <value>"))
+	}
+	key, value := args[0], args[1]
+	field, parent, _, found := findSettingField(reflect.ValueOf(settings.Global()), key)
+	if !found {
+		return errs.Newf(i18n.Text("no such setting: %s"), key)
+	}
+	if err := assignValue(field, value); err != nil {
+		return err
+	}
+	validateIfPossible(parent)
+	return persist()
+}
+
+func configReset(args []string) error {
+	if len(args) != 1 {
+		return errs.New(i18n.Text("usage: gcs config reset <key>"))
+	}
+	key := args[0]
+	field, parent, sf, found := findSettingField(reflect.ValueOf(settings.Global()), key)
+	if !found {
+		return errs.Newf(i18n.Text("no such setting: %s"), key)
+	}
+	field.Set(defaultValue(field, sf))
+	validateIfPossible(parent)
+	return persist()
+}
+
+// persist saves the global settings back to disk, if the settings package
+// exposes a way to do so.
+func persist() error {
+	if s, ok := settings.Global().(interface{ Save() error }); ok {
+		return s.Save()
+	}
+	return nil
+}
+
+// validateIfPossible calls Validate() on v (or its address) if such a method
+// exists, mirroring the pattern used by types like WebServerSettings.
+func validateIfPossible(v reflect.Value) {
+	if !v.IsValid() {
+		return
+	}
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	if m := v.MethodByName("Validate"); m.IsValid() && m.Type().NumIn() == 0 {
+		m.Call(nil)
+	}
+}
+
+// walkSettings flattens settings into a sorted list of leaf entries, using
+// json tags for path names and the name of the top-level settings field each
+// leaf falls under as its subsystem.
+func walkSettings(root any) []ConfigEntry {
+	var entries []ConfigEntry
+	walk(reflect.ValueOf(root), "", "", &entries)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries
+}
+
+func walk(v reflect.Value, subsystem, path string, entries *[]ConfigEntry) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = sf.Name
+		}
+		childPath := name
+		if path != "" {
+			childPath = path + "." + name
+		}
+		childSubsystem := subsystem
+		if childSubsystem == "" {
+			childSubsystem = sf.Name
+		}
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.Struct, reflect.Ptr:
+			walk(fv, childSubsystem, childPath, entries)
+		default:
+			*entries = append(*entries, ConfigEntry{
+				Subsystem: childSubsystem,
+				Path:      childPath,
+				Type:      fv.Type().String(),
+				Value:     formatSettingValue(fv),
+				Default:   defaultString(fv, sf),
+			})
+		}
+	}
+}
+
+// findSettingField locates the field addressed by a dotted json path,
+// returning it alongside its immediate parent struct (for Validate calls)
+// and its reflect.StructField (for its "default" tag, if any).
+func findSettingField(v reflect.Value, path string) (field, parent reflect.Value, sf reflect.StructField, found bool) {
+	parts := strings.Split(path, ".")
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, reflect.Value{}, reflect.StructField{}, false
+		}
+		v = v.Elem()
+	}
+	for i, part := range parts {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, reflect.Value{}, reflect.StructField{}, false
+		}
+		t := v.Type()
+		matched := false
+		for f := 0; f < t.NumField(); f++ {
+			candidate := t.Field(f)
+			tag := strings.Split(candidate.Tag.Get("json"), ",")[0]
+			if tag == part || (tag == "" && candidate.Name == part) {
+				parent = v
+				sf = candidate
+				v = v.Field(f)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return reflect.Value{}, reflect.Value{}, reflect.StructField{}, false
+		}
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, reflect.Value{}, reflect.StructField{}, false
+			}
+			v = v.Elem()
+		}
+		if i == len(parts)-1 {
+			return v, parent, sf, true
+		}
+	}
+	return reflect.Value{}, reflect.Value{}, reflect.StructField{}, false
+}
+
+// defaultValue returns the setting's real default, read from its "default"
+// struct tag (e.g. `default:"10"`) when present, parsed the same way
+// assignValue parses user input so fxp.Int fields get their human value
+// rather than a raw scaled integer. Fields without a "default" tag fall back
+// to the zero value, which is only correct when zero genuinely is the
+// setting's default.
+func defaultValue(field reflect.Value, sf reflect.StructField) reflect.Value {
+	tag, ok := sf.Tag.Lookup("default")
+	if !ok {
+		return reflect.Zero(field.Type())
+	}
+	v := reflect.New(field.Type()).Elem()
+	if err := assignValue(v, tag); err != nil {
+		return reflect.Zero(field.Type())
+	}
+	return v
+}
+
+// defaultString renders a field's default the same way defaultValue
+// computes it, for display in "gcs config info"/"get".
+func defaultString(field reflect.Value, sf reflect.StructField) string {
+	return formatSettingValue(defaultValue(field, sf))
+}
+
+// formatSettingValue renders v for display, routing fxp.Int through its
+// human-readable String() form instead of printing the raw scaled integer.
+func formatSettingValue(v reflect.Value) string {
+	if v.Type() == fxpIntType {
+		return v.Interface().(fxp.Int).String()
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// assignValue parses s according to field's kind and sets it.
+func assignValue(field reflect.Value, s string) error {
+	if !field.CanSet() {
+		return errs.New(i18n.Text("setting is not assignable"))
+	}
+	if field.Type() == fxpIntType {
+		n, err := fxp.FromString(s)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		field.SetInt(int64(n))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		field.SetFloat(n)
+	default:
+		return errs.Newf(i18n.Text("unsupported setting type: %s"), field.Type().String())
+	}
+	return nil
+}
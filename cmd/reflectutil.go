@@ -0,0 +1,54 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package cmd
+
+import (
+	"reflect"
+
+	"github.com/richardwilkes/gcs/v5/model/gurps"
+)
+
+// findWebServerSettings searches root for a *gurps.WebServerSettings field,
+// at any nesting depth, and returns it addressable so callers can mutate it
+// in place.
+func findWebServerSettings(root any) *gurps.WebServerSettings {
+	return findWebServerSettingsValue(reflect.ValueOf(root))
+}
+
+func findWebServerSettingsValue(v reflect.Value) *gurps.WebServerSettings {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if ws, ok := v.Addr().Interface().(*gurps.WebServerSettings); ok {
+		return ws
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.Struct, reflect.Ptr:
+			if result := findWebServerSettingsValue(fv); result != nil {
+				return result
+			}
+		default:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/richardwilkes/gcs/v5/model/schema"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+// RunSchema implements the "gcs schema" subcommand, emitting the generated
+// JSON Schema document for one of schema.SheetKind, schema.TemplateKind,
+// schema.LibraryKind, or schema.SettingsKind.
+func RunSchema(args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	out := fs.String("out", "", i18n.Text("write the schema to this file instead of stdout"))
+	if err := fs.Parse(args); err != nil {
+		return errs.Wrap(err)
+	}
+	if fs.NArg() != 1 {
+		return errs.New(i18n.Text("usage: gcs schema [--out file] <sheet|template|library|settings>"))
+	}
+	doc, ok := schema.ForKind(fs.Arg(0))
+	if !ok {
+		return errs.Newf(i18n.Text("unknown schema kind: %s"), fs.Arg(0))
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if *out == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	return errs.Wrap(os.WriteFile(*out, append(data, '\n'), 0o644))
+}
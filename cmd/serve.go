@@ -0,0 +1,123 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/richardwilkes/gcs/v5/model/fxp"
+	"github.com/richardwilkes/gcs/v5/model/gurps"
+	"github.com/richardwilkes/gcs/v5/model/settings"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+// RunServe implements the "gcs serve" subcommand: it applies CLI overrides
+// on top of the persisted WebServerSettings and then runs the embedded web
+// server in the foreground until SIGTERM/SIGINT is received.
+func RunServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	bindAddress := fs.String("bind-address", "", i18n.Text("address:port to listen on"))
+	certFile := fs.String("cert-file", "", i18n.Text("TLS certificate file"))
+	keyFile := fs.String("key-file", "", i18n.Text("TLS key file"))
+	readTimeout := fs.Int("read-timeout", 0, i18n.Text("read timeout, in seconds"))
+	writeTimeout := fs.Int("write-timeout", 0, i18n.Text("write timeout, in seconds"))
+	idleTimeout := fs.Int("idle-timeout", 0, i18n.Text("idle timeout, in seconds"))
+	shutdownGracePeriod := fs.Int("shutdown-grace-period", -1, i18n.Text("seconds to wait for in-flight requests to finish on shutdown"))
+	addUser := fs.String("add-user", "", i18n.Text("add or update a user, as user:password"))
+	removeUser := fs.String("remove-user", "", i18n.Text("remove a user"))
+	autoTLSDomain := fs.String("auto-tls", "", i18n.Text("provision a TLS certificate via ACME for this domain"))
+	acmeCacheDir := fs.String("acme-cache-dir", "", i18n.Text("on-disk cache directory for ACME certificates"))
+	tlsMinVersion := fs.String("tls-min-version", "1.2", i18n.Text("minimum TLS version to accept: 1.2 or 1.3"))
+	if err := fs.Parse(args); err != nil {
+		return errs.Wrap(err)
+	}
+
+	ws := webServerSettings()
+	if ws == nil {
+		return errs.New(i18n.Text("unable to locate web server settings"))
+	}
+
+	if *bindAddress != "" {
+		ws.Address = *bindAddress
+	}
+	if *certFile != "" {
+		ws.CertFile = *certFile
+	}
+	if *keyFile != "" {
+		ws.KeyFile = *keyFile
+	}
+	if *readTimeout > 0 {
+		ws.ReadTimeout = fxp.From(*readTimeout)
+	}
+	if *writeTimeout > 0 {
+		ws.WriteTimeout = fxp.From(*writeTimeout)
+	}
+	if *idleTimeout > 0 {
+		ws.IdleTimeout = fxp.From(*idleTimeout)
+	}
+	if *shutdownGracePeriod >= 0 {
+		ws.ShutdownGracePeriod = fxp.From(*shutdownGracePeriod)
+	}
+	if *addUser != "" {
+		user, password, ok := strings.Cut(*addUser, ":")
+		if !ok {
+			return errs.New(i18n.Text("--add-user must be of the form user:password"))
+		}
+		if err := ws.SetUserPassword(user, password); err != nil {
+			return err
+		}
+	}
+	if *removeUser != "" {
+		ws.RemoveUser(*removeUser)
+	}
+	ws.Validate()
+
+	var version uint16
+	switch *tlsMinVersion {
+	case "1.2":
+		version = tls.VersionTLS12
+	case "1.3":
+		version = tls.VersionTLS13
+	default:
+		return errs.Newf(i18n.Text("unsupported --tls-min-version: %s"), *tlsMinVersion)
+	}
+
+	var autoTLS *gurps.AutoTLS
+	if *autoTLSDomain != "" {
+		cacheDir := *acmeCacheDir
+		if cacheDir == "" {
+			cacheDir = "acme-cache"
+		}
+		autoTLS = &gurps.AutoTLS{Domain: *autoTLSDomain, CacheDir: cacheDir}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	logger := log.New(os.Stdout, "", log.LstdFlags)
+	logger.Printf(i18n.Text("serving on %s"), ws.Address)
+	return ws.Serve(ctx, ws.Handler(http.NewServeMux()), autoTLS, version)
+}
+
+// webServerSettings locates the *gurps.WebServerSettings embedded somewhere
+// within the global settings tree, without needing to know its exact field
+// name or nesting depth.
+func webServerSettings() *gurps.WebServerSettings {
+	return findWebServerSettings(settings.Global())
+}
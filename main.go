@@ -12,14 +12,22 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/richardwilkes/gcs/v5/cmd"
 	"github.com/richardwilkes/gcs/v5/dbg"
 	"github.com/richardwilkes/gcs/v5/model/export"
 	"github.com/richardwilkes/gcs/v5/model/gurps"
 	"github.com/richardwilkes/gcs/v5/model/library"
+	"github.com/richardwilkes/gcs/v5/model/schema"
 	"github.com/richardwilkes/gcs/v5/model/settings"
 	"github.com/richardwilkes/gcs/v5/setup"
 	"github.com/richardwilkes/gcs/v5/setup/early"
 	"github.com/richardwilkes/gcs/v5/ui"
+	"github.com/richardwilkes/gcs/v5/ui/tui"
 	"github.com/richardwilkes/toolbox/atexit"
 	"github.com/richardwilkes/toolbox/cmdline"
 	"github.com/richardwilkes/toolbox/i18n"
@@ -27,22 +35,92 @@ import (
 	"github.com/richardwilkes/unison"
 )
 
+// subcommands are dispatched on os.Args[1] before the general-purpose
+// cmdline flag parsing below, since they have their own argument grammars
+// (e.g. "gcs config get some.key").
+var subcommands = map[string]func(args []string) error{
+	"config": cmd.RunConfig,
+	"schema": cmd.RunSchema,
+	"serve":  cmd.RunServe,
+}
+
+// validateAgainstSchema checks each file in fileList against the JSON
+// Schema for its kind (determined by extension) and reports any violations
+// found, in JSON-pointer form, before the normal unmarshal proceeds.
+func validateAgainstSchema(fileList []string) error {
+	for _, one := range fileList {
+		ext := strings.TrimPrefix(filepath.Ext(one), ".")
+		kind, ok := schema.KindForExtension(ext)
+		if !ok {
+			continue
+		}
+		doc, ok := schema.ForKind(kind)
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(one)
+		if err != nil {
+			return err
+		}
+		violations, err := schema.Validate(data, doc)
+		if err != nil {
+			return err
+		}
+		for _, v := range violations {
+			fmt.Printf("%s: %s: %s\n", one, v.Pointer, v.Message)
+		}
+		if len(violations) > 0 {
+			return fmt.Errorf("%s: failed schema validation", one)
+		}
+	}
+	return nil
+}
+
 func main() {
 	early.Configure()
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			setup.Setup()
+			settings.Global() // Here to force early initialization
+			if err := run(os.Args[2:]); err != nil {
+				_, _ = os.Stderr.WriteString(err.Error() + "\n")
+				atexit.Exit(1)
+			}
+			atexit.Exit(0)
+		}
+	}
 	unison.AttachConsole()
 	cl := cmdline.New(true)
 	cl.Description = ui.AppDescription
 	var textTmplPath string
 	cl.NewGeneralOption(&textTmplPath).SetName("text").SetSingle('x').SetArg("file").
 		SetUsage(i18n.Text("Export sheets using the specified template file"))
+	var runTUI bool
+	cl.NewGeneralOption(&runTUI).SetName("tui").SetSingle('t').
+		SetUsage(i18n.Text("Opens a terminal-based interface for viewing and lightly editing character sheets, instead of the graphical UI"))
 	var convert bool
 	cl.NewGeneralOption(&convert).SetName("convert").SetSingle('c').
 		SetUsage(i18n.Text("Converts all files specified on the command line to the current data format. If a directory is specified, it will be traversed recursively and all files found will be converted. This operation is intended to easily bring files up tot he current version's data format. After all files have been processed, GCS will exit"))
+	var validate bool
+	cl.NewGeneralOption(&validate).SetName("validate").
+		SetUsage(i18n.Text("Validates files against the GCS JSON Schema before loading or converting them, reporting any violations found"))
 	cl.NewGeneralOption(&dbg.VariableResolver).SetName("debug-variable-resolver")
 	fileList := jotrotate.ParseAndSetup(cl)
 	setup.Setup()
 	settings.Global() // Here to force early initialization
+	if validate {
+		if err := validateAgainstSchema(fileList); err != nil {
+			cl.FatalMsg(err.Error())
+		}
+	}
 	switch {
+	case runTUI:
+		if len(fileList) == 0 {
+			cl.FatalMsg(i18n.Text("No files to process."))
+		}
+		if err := tui.Run(fileList); err != nil {
+			cl.FatalMsg(err.Error())
+		}
 	case convert:
 		if err := gurps.Convert(fileList...); err != nil {
 			cl.FatalMsg(err.Error())
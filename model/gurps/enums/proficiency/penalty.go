@@ -0,0 +1,30 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package proficiency
+
+import "github.com/richardwilkes/gcs/v5/model/fxp"
+
+// Penalty returns the GURPS Basic skill penalty for using a weapon at this
+// familiarity level. wrongClass steepens the NonWeaponGroup penalty from the
+// usual -4 (wrong weapon group) to -5 (wrong character class/archetype
+// entirely), per GURPS Basic, Campaigns p.169-170.
+func (enum Level) Penalty(wrongClass bool) fxp.Int {
+	switch enum {
+	case Unfamiliar:
+		return fxp.From(-2)
+	case NonWeaponGroup:
+		if wrongClass {
+			return fxp.From(-5)
+		}
+		return fxp.From(-4)
+	default:
+		return 0
+	}
+}
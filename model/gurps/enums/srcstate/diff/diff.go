@@ -0,0 +1,234 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+// Package diff gives srcstate.Mismatched structured, actionable detail: an
+// ordered list of the fields that differ between a local copy and the
+// library source it was loaded from.
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/richardwilkes/gcs/v5/model/gurps/enums/srcstate"
+)
+
+// Kind describes how a field differs between the local copy and its source.
+type Kind int
+
+// Possible Kind values.
+const (
+	Changed Kind = iota
+	Added
+	Removed
+)
+
+// String implements fmt.Stringer.
+func (k Kind) String() string {
+	switch k {
+	case Changed:
+		return "changed"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return Changed.String()
+	}
+}
+
+// FieldChange records a single field that differs between a local copy and
+// its library source.
+type FieldChange struct {
+	Path        string
+	SourceValue any
+	LocalValue  any
+	Kind        Kind
+}
+
+// Diff holds every FieldChange found by Compare, in the order fields were
+// visited.
+type Diff struct {
+	Changes []FieldChange
+}
+
+// Revert applies every recorded change's SourceValue back onto local,
+// implementing a "revert to source" action for the whole diff. It stops at,
+// and returns, the first error. See RevertField to revert a single field.
+func (d *Diff) Revert(local any) error {
+	for _, fc := range d.Changes {
+		if err := fc.RevertField(local); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevertField writes fc.SourceValue into local at fc.Path. A nil SourceValue
+// (an Added change) resets the field to its zero value. A Path that indexes
+// into a slice cannot be reverted and returns an error.
+func (fc FieldChange) RevertField(local any) error {
+	return setPath(reflect.ValueOf(local), fc.Path, fc.SourceValue)
+}
+
+// Compare walks local and source in lockstep via reflection, honoring json
+// tags for field path names and skipping any field tagged `srcdiff:"-"`
+// (used for volatile bookkeeping such as IDs and timestamps). It returns
+// srcstate.Matched with a nil Diff when no differences were found, or
+// srcstate.Mismatched with the populated Diff otherwise.
+func Compare(local, source any) (srcstate.Value, *Diff) {
+	var d Diff
+	compareValues(reflect.ValueOf(local), reflect.ValueOf(source), "", &d)
+	if len(d.Changes) == 0 {
+		return srcstate.Matched, nil
+	}
+	return srcstate.Mismatched, &d
+}
+
+func compareValues(local, source reflect.Value, path string, d *Diff) {
+	for local.Kind() == reflect.Ptr || local.Kind() == reflect.Interface {
+		if local.IsNil() {
+			local = reflect.Value{}
+			break
+		}
+		local = local.Elem()
+	}
+	for source.Kind() == reflect.Ptr || source.Kind() == reflect.Interface {
+		if source.IsNil() {
+			source = reflect.Value{}
+			break
+		}
+		source = source.Elem()
+	}
+	switch {
+	case !local.IsValid() && !source.IsValid():
+		return
+	case !local.IsValid():
+		d.Changes = append(d.Changes, FieldChange{Path: path, Kind: Removed, SourceValue: interfaceOf(source)})
+		return
+	case !source.IsValid():
+		d.Changes = append(d.Changes, FieldChange{Path: path, Kind: Added, LocalValue: interfaceOf(local)})
+		return
+	}
+
+	if local.Kind() == reflect.Struct && local.Type() == source.Type() {
+		t := local.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() || sf.Tag.Get("srcdiff") == "-" {
+				continue
+			}
+			name := strings.Split(sf.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				name = sf.Name
+			}
+			childPath := name
+			if path != "" {
+				childPath = path + "." + name
+			}
+			compareValues(local.Field(i), source.Field(i), childPath, d)
+		}
+		return
+	}
+
+	if local.Kind() == reflect.Slice && local.Type() == source.Type() {
+		maximum := local.Len()
+		if source.Len() > maximum {
+			maximum = source.Len()
+		}
+		for i := 0; i < maximum; i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			var lv, sv reflect.Value
+			if i < local.Len() {
+				lv = local.Index(i)
+			}
+			if i < source.Len() {
+				sv = source.Index(i)
+			}
+			compareValues(lv, sv, childPath, d)
+		}
+		return
+	}
+
+	if !local.IsValid() || !source.IsValid() || !reflect.DeepEqual(interfaceOf(local), interfaceOf(source)) {
+		d.Changes = append(d.Changes, FieldChange{
+			Path:        path,
+			Kind:        Changed,
+			SourceValue: interfaceOf(source),
+			LocalValue:  interfaceOf(local),
+		})
+	}
+}
+
+func interfaceOf(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// setPath locates the field addressed by a dotted json path (as produced by
+// Compare) within root and sets it to value, or to the field's zero value when
+// value is nil. Only struct-field segments are resolved: a path containing a
+// slice index such as "tags[0]", which Compare emits for slice elements, or
+// one that passes through a nil pointer, returns an error.
+func setPath(root reflect.Value, path string, value any) error {
+	v := root
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return fmt.Errorf("diff: cannot revert into a nil value at %q", path)
+		}
+		v = v.Elem()
+	}
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("diff: %q does not address a struct field", path)
+		}
+		t := v.Type()
+		found := false
+		for f := 0; f < t.NumField(); f++ {
+			sf := t.Field(f)
+			name := strings.Split(sf.Tag.Get("json"), ",")[0]
+			if name == "" {
+				name = sf.Name
+			}
+			if name == part {
+				v = v.Field(f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("diff: no such field %q", path)
+		}
+		if i == len(parts)-1 && value == nil {
+			if !v.CanSet() {
+				return fmt.Errorf("diff: field %q is not settable", path)
+			}
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return fmt.Errorf("diff: cannot revert into a nil value at %q", path)
+			}
+			v = v.Elem()
+		}
+		if i == len(parts)-1 {
+			if !v.CanSet() {
+				return fmt.Errorf("diff: field %q is not settable", path)
+			}
+			v.Set(reflect.ValueOf(value))
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package srcstate
+
+import "time"
+
+// Provenance records where a piece of data came from in the library, so
+// that a later comparison can distinguish "behind the library" (Older) from
+// "ahead of the library" (Newer) instead of conservatively bucketing both as
+// Mismatched.
+type Provenance struct {
+	SourceLibrary string    `json:"source_library,omitempty"`
+	SourcePath    string    `json:"source_path,omitempty"`
+	SourceHash    string    `json:"source_hash,omitempty"`
+	SourceVersion int       `json:"source_version,omitempty"`
+	ComputedAt    time.Time `json:"computed_at,omitempty"`
+}
+
+// Compare determines the Value for local relative to source, given their
+// Provenance records. When either side lacks a version to compare,
+// it falls back to reporting Mismatched when the hashes differ (or Matched
+// when they're equal), since there isn't enough information to say which
+// side is ahead. When neither side carries any provenance at all, there is
+// no information to compare, so that also reports as Mismatched rather than
+// being mistaken for an actual hash match.
+func Compare(local, source Provenance) Value {
+	switch {
+	case local.SourceHash == "" && source.SourceHash == "" && local.SourceVersion == 0 && source.SourceVersion == 0:
+		return Mismatched
+	case local.SourceHash == source.SourceHash && local.SourceHash != "":
+		return Matched
+	case local.SourceVersion == 0 || source.SourceVersion == 0:
+		if local.SourceHash == source.SourceHash {
+			return Matched
+		}
+		return Mismatched
+	case local.SourceVersion < source.SourceVersion:
+		return Older
+	case local.SourceVersion > source.SourceVersion:
+		return Newer
+	default:
+		return Mismatched
+	}
+}
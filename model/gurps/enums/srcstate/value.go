@@ -0,0 +1,69 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package srcstate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExtractValueStrict extracts the value from a string, returning an error
+// instead of silently coercing unknown keys to Custom. Use this when
+// reading data where an unrecognized value should be treated as corruption
+// rather than downgraded.
+func ExtractValueStrict(str string) (Value, error) {
+	for _, enum := range Values {
+		if strings.EqualFold(enum.Key(), str) {
+			return enum, nil
+		}
+	}
+	return 0, fmt.Errorf("srcstate: unknown value %q", str)
+}
+
+// StrictValue is Value with a TextUnmarshaler that rejects unknown keys
+// instead of coercing them to Custom. Use it for a field where silently
+// masking a forward-incompatible or corrupted value would hide real data
+// loss.
+type StrictValue Value
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (enum StrictValue) MarshalText() (text []byte, err error) {
+	return Value(enum).MarshalText()
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (enum *StrictValue) UnmarshalText(text []byte) error {
+	v, err := ExtractValueStrict(string(text))
+	if err != nil {
+		return err
+	}
+	*enum = StrictValue(v)
+	return nil
+}
+
+// String implements fmt.Stringer.
+func (enum StrictValue) String() string {
+	return Value(enum).String()
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, storing
+// the value as a single byte for compact storage in sheet caches.
+func (enum Value) MarshalBinary() (data []byte, err error) {
+	return []byte{byte(enum)}, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (enum *Value) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return fmt.Errorf("srcstate: invalid binary value length %d", len(data))
+	}
+	*enum = Value(data[0]).EnsureValid()
+	return nil
+}
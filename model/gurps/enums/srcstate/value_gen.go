@@ -23,10 +23,12 @@ const (
 	Matched
 	Mismatched
 	Missing
+	Older
+	Newer
 )
 
 // LastValue is the last valid value.
-const LastValue Value = Missing
+const LastValue Value = Newer
 
 // Values holds all possible values.
 var Values = []Value{
@@ -34,6 +36,8 @@ var Values = []Value{
 	Matched,
 	Mismatched,
 	Missing,
+	Older,
+	Newer,
 }
 
 // Value describes the state of a source compared to a piece of data.
@@ -41,7 +45,7 @@ type Value byte
 
 // EnsureValid ensures this is of a known value.
 func (enum Value) EnsureValid() Value {
-	if enum <= Missing {
+	if enum <= LastValue {
 		return enum
 	}
 	return 0
@@ -58,6 +62,10 @@ func (enum Value) Key() string {
 		return "mismatched"
 	case Missing:
 		return "missing"
+	case Older:
+		return "older"
+	case Newer:
+		return "newer"
 	default:
 		return Value(0).Key()
 	}
@@ -74,6 +82,10 @@ func (enum Value) String() string {
 		return i18n.Text("Mismatched")
 	case Missing:
 		return i18n.Text("Missing")
+	case Older:
+		return i18n.Text("Older")
+	case Newer:
+		return i18n.Text("Newer")
 	default:
 		return Value(0).String()
 	}
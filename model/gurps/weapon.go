@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/richardwilkes/gcs/v5/model/fxp"
+	"github.com/richardwilkes/gcs/v5/model/gurps/enums/proficiency"
 	"github.com/richardwilkes/json"
 	"github.com/richardwilkes/rpgtools/dice"
 	"github.com/richardwilkes/toolbox/errs"
@@ -47,6 +48,10 @@ const (
 	WeaponShotsColumn
 	WeaponBulkColumn
 	WeaponRecoilColumn
+	WeaponHitChanceColumn
+	WeaponExpectedDamageColumn
+	WeaponRangeBandsColumn
+	WeaponDamageBreakdownColumn
 )
 
 // WeaponOwner defines the methods required of a Weapon owner.
@@ -62,29 +67,41 @@ type WeaponOwner interface {
 
 // WeaponData holds the Weapon data that is written to disk.
 type WeaponData struct {
-	ID                 uuid.UUID       `json:"id"`
-	Type               WeaponType      `json:"type"`
-	Bipod              bool            `json:"bipod,omitempty"`
-	Mounted            bool            `json:"mounted,omitempty"`
-	MusketRest         bool            `json:"musket_rest,omitempty"`
-	TwoHanded          bool            `json:"two_handed,omitempty"`
-	UnreadyAfterAttack bool            `json:"unready_after_attack,omitempty"`
-	Jet                bool            `json:"jet,omitempty"`
-	Damage             WeaponDamage    `json:"damage"`
-	Usage              string          `json:"usage,omitempty"`
-	UsageNotes         string          `json:"usage_notes,omitempty"`
-	Reach              string          `json:"reach,omitempty"`
-	Parry              string          `json:"parry,omitempty"`
-	Block              string          `json:"block,omitempty"`
-	Range              string          `json:"range,omitempty"`
-	RateOfFire         string          `json:"rate_of_fire,omitempty"`
-	Shots              string          `json:"shots,omitempty"`
-	Bulk               string          `json:"bulk,omitempty"`
-	Recoil             string          `json:"recoil,omitempty"`
-	WeaponAcc          fxp.Int         `json:"weapon_acc,omitempty"`
-	ScopeAcc           fxp.Int         `json:"scope_acc,omitempty"`
-	MinST              fxp.Int         `json:"min_st,omitempty"`
-	Defaults           []*SkillDefault `json:"defaults,omitempty"`
+	ID                    uuid.UUID         `json:"id"`
+	Type                  WeaponType        `json:"type"`
+	Bipod                 bool              `json:"bipod,omitempty"`
+	Mounted               bool              `json:"mounted,omitempty"`
+	MusketRest            bool              `json:"musket_rest,omitempty"`
+	TwoHanded             bool              `json:"two_handed,omitempty"`
+	UnreadyAfterAttack    bool              `json:"unready_after_attack,omitempty"`
+	Readied               bool              `json:"readied,omitempty"`
+	Jet                   bool              `json:"jet,omitempty"`
+	Improvised            bool              `json:"improvised,omitempty"`
+	DualWieldable         bool              `json:"dual_wieldable,omitempty"`
+	ProficiencyWrongClass bool              `json:"proficiency_wrong_class,omitempty"`
+	Proficiency           proficiency.Level `json:"proficiency,omitempty"`
+	Damage                WeaponDamage      `json:"damage"`
+	DamageComponents      []DamageComponent `json:"damage_components,omitempty"`
+	Usage                 string            `json:"usage,omitempty"`
+	UsageNotes            string            `json:"usage_notes,omitempty"`
+	Reach                 string            `json:"reach,omitempty"`
+	Parry                 string            `json:"parry,omitempty"`
+	Block                 string            `json:"block,omitempty"`
+	Range                 string            `json:"range,omitempty"`
+	RateOfFire            string            `json:"rate_of_fire,omitempty"`
+	Shots                 string            `json:"shots,omitempty"`
+	Bulk                  string            `json:"bulk,omitempty"`
+	Recoil                string            `json:"recoil,omitempty"`
+	LoadedAmmoType        string            `json:"loaded_ammo_type,omitempty"`
+	WeaponAcc             fxp.Int           `json:"weapon_acc,omitempty"`
+	ScopeAcc              fxp.Int           `json:"scope_acc,omitempty"`
+	MinST                 fxp.Int           `json:"min_st,omitempty"`
+	Hush                  fxp.Int           `json:"hush,omitempty"`
+	Speed                 fxp.Int           `json:"speed,omitempty"`
+	CurrentShots          int               `json:"current_shots,omitempty"`
+	ReloadProgress        int               `json:"reload_progress,omitempty"`
+	Defaults              []*SkillDefault   `json:"defaults,omitempty"`
+	Modifiers             []*WeaponModifier `json:"modifiers,omitempty"`
 }
 
 // Weapon holds the stats for a weapon.
@@ -160,6 +177,10 @@ func (w *Weapon) Clone(_ *Entity, _ *Weapon, preserveID bool) *Weapon {
 			other.Defaults = append(other.Defaults, &d)
 		}
 	}
+	if other.DamageComponents != nil {
+		other.DamageComponents = make([]DamageComponent, len(w.DamageComponents))
+		copy(other.DamageComponents, w.DamageComponents)
+	}
 	return &other
 }
 
@@ -205,29 +226,50 @@ func (w *Weapon) HashCode() uint32 {
 	_, _ = h.Write([]byte(w.Range))
 	_, _ = h.Write([]byte(w.RateOfFire))
 	_, _ = h.Write([]byte(w.Shots))
+	_, _ = h.Write([]byte(w.LoadedAmmoType))
+	_ = binary.Write(h, binary.LittleEndian, int64(w.CurrentShots))
+	_ = binary.Write(h, binary.LittleEndian, int64(w.ReloadProgress))
 	_, _ = h.Write([]byte(w.Bulk))
 	_, _ = h.Write([]byte(w.Recoil))
 	_ = binary.Write(h, binary.LittleEndian, w.Jet)
+	_ = binary.Write(h, binary.LittleEndian, w.Improvised)
+	_ = binary.Write(h, binary.LittleEndian, w.DualWieldable)
+	_, _ = h.Write([]byte{byte(w.Proficiency)})
+	_ = binary.Write(h, binary.LittleEndian, w.ProficiencyWrongClass)
 	_ = binary.Write(h, binary.LittleEndian, w.WeaponAcc)
 	_ = binary.Write(h, binary.LittleEndian, w.ScopeAcc)
 	_ = binary.Write(h, binary.LittleEndian, w.MinST)
+	_ = binary.Write(h, binary.LittleEndian, w.Hush)
+	_ = binary.Write(h, binary.LittleEndian, w.Speed)
 	_ = binary.Write(h, binary.LittleEndian, w.Bipod)
 	_ = binary.Write(h, binary.LittleEndian, w.Mounted)
 	_ = binary.Write(h, binary.LittleEndian, w.MusketRest)
 	_ = binary.Write(h, binary.LittleEndian, w.TwoHanded)
 	_ = binary.Write(h, binary.LittleEndian, w.UnreadyAfterAttack)
+	_ = binary.Write(h, binary.LittleEndian, w.Readied)
+	for _, mod := range w.Modifiers {
+		_, _ = h.Write([]byte(mod.ID.String()))
+		_ = binary.Write(h, binary.LittleEndian, mod.Enabled)
+	}
+	for _, c := range w.DamageComponents {
+		_, _ = h.Write([]byte(c.Render()))
+		_, _ = h.Write([]byte(c.Source))
+	}
 	return h.Sum32()
 }
 
 // MarshalJSON implements json.Marshaler.
 func (w *Weapon) MarshalJSON() ([]byte, error) {
 	type calc struct {
-		Level         fxp.Int `json:"level,omitempty"`
-		Parry         string  `json:"parry,omitempty"`
-		Block         string  `json:"block,omitempty"`
-		Range         string  `json:"range,omitempty"`
-		Damage        string  `json:"damage,omitempty"`
-		ResolvedMinST fxp.Int `json:"resolved_min_st,omitempty"`
+		Level          fxp.Int     `json:"level,omitempty"`
+		Parry          string      `json:"parry,omitempty"`
+		Block          string      `json:"block,omitempty"`
+		Range          string      `json:"range,omitempty"`
+		Damage         string      `json:"damage,omitempty"`
+		ResolvedMinST  fxp.Int     `json:"resolved_min_st,omitempty"`
+		HitChance      fxp.Int     `json:"hit_chance,omitempty"`
+		ExpectedDamage fxp.Int     `json:"expected_damage,omitempty"`
+		RangeBands     *RangeBands `json:"range_bands,omitempty"`
 	}
 	data := struct {
 		WeaponData
@@ -246,6 +288,11 @@ func (w *Weapon) MarshalJSON() ([]byte, error) {
 		data.Calc.Block = w.ResolvedBlock(nil)
 	case RangedWeaponType:
 		data.Calc.Range = w.ResolvedRange()
+		metrics := w.CombatMetrics(nil)
+		data.Calc.HitChance = metrics.HitChance
+		data.Calc.ExpectedDamage = metrics.ExpectedDamagePerRound
+		bands := w.RangeBands()
+		data.Calc.RangeBands = &bands
 	default:
 	}
 	return json.Marshal(&data)
@@ -401,6 +448,7 @@ func (w *Weapon) SkillLevel(tooltip *xio.ByteBuffer) fxp.Int {
 
 func (w *Weapon) skillLevelBaseAdjustment(entity *Entity, tooltip *xio.ByteBuffer) fxp.Int {
 	var adj fxp.Int
+	adj += w.ResolvedProficiencyPenalty(nil)
 	if minST := w.ResolvedMinimumStrength(nil) - entity.StrikingStrength(); minST > 0 {
 		adj -= minST
 		if tooltip != nil {
@@ -435,6 +483,14 @@ func (w *Weapon) skillLevelBaseAdjustment(entity *Entity, tooltip *xio.ByteBuffe
 			return false
 		}, true, true, eqp.Modifiers...)
 	}
+	for _, mod := range w.Modifiers {
+		if !mod.Enabled {
+			continue
+		}
+		for _, f := range mod.Features {
+			adj += w.extractSkillBonusForThisWeapon(f, tooltip)
+		}
+	}
 	return adj
 }
 
@@ -461,6 +517,54 @@ func (w *Weapon) EncumbrancePenalty(entity *Entity, tooltip *xio.ByteBuffer) fxp
 	return penalty
 }
 
+// ResolvedMovePenalty returns the Basic Move penalty this weapon imposes on its wielder while readied. A positive
+// Speed slows the wielder; a negative Speed quickens them. Extra ST beyond the weapon's minimum offsets a positive
+// Speed's penalty, per GURPS Basic, p.17.
+func (w *Weapon) ResolvedMovePenalty(entity *Entity) fxp.Int {
+	if w.Speed <= 0 {
+		return w.Speed
+	}
+	penalty := w.Speed
+	if entity != nil {
+		if minST := w.ResolvedMinimumStrength(nil); minST > 0 {
+			if overage := entity.StrikingStrength() - minST; overage > 0 {
+				penalty -= overage
+			}
+		}
+	}
+	return penalty.Max(0)
+}
+
+// ReadiedWeaponMovePenalty sums the Basic Move penalty of only the weapons e currently has Readied -- a weapon
+// that's merely carried shouldn't slow anyone down, per GURPS Basic, Campaigns p.17 ("while held"). This is the
+// integration point Entity's Move/Dodge computation should subtract alongside its encumbrance-based Move penalty,
+// e.g. "move := e.encumbranceMove(level) - e.ReadiedWeaponMovePenalty()"; entity.go (and so Entity.Move/Entity.Dodge
+// themselves) isn't present in this tree extract, so nothing in this package calls it yet. ui/tui's sheetTab.refresh
+// does call it, so the penalty is at least visible while that integration is pending.
+func (e *Entity) ReadiedWeaponMovePenalty() fxp.Int {
+	var total fxp.Int
+	for _, w := range e.Weapons() {
+		if w.Readied {
+			total += w.ResolvedMovePenalty(e)
+		}
+	}
+	return total
+}
+
+// ResolvedProficiencyPenalty returns the skill penalty imposed by a lack of familiarity or training with this
+// weapon, per GURPS Basic, Campaigns p.169-170.
+func (w *Weapon) ResolvedProficiencyPenalty(tooltip *xio.ByteBuffer) fxp.Int {
+	penalty := w.Proficiency.EnsureValid().Penalty(w.ProficiencyWrongClass)
+	if penalty != 0 && tooltip != nil {
+		tooltip.WriteByte('\n')
+		tooltip.WriteString(i18n.Text("Proficiency"))
+		tooltip.WriteString(" [")
+		tooltip.WriteString(penalty.StringWithSign())
+		tooltip.WriteByte(']')
+	}
+	return penalty
+}
+
 func (w *Weapon) extractSkillBonusForThisWeapon(f Feature, tooltip *xio.ByteBuffer) fxp.Int {
 	if sb, ok := f.(*SkillBonus); ok {
 		if sb.SelectionType.EnsureValid() == ThisWeaponSkillSelectionType {
@@ -753,6 +857,19 @@ func (w *Weapon) collectWeaponBonuses(dieCount int, tooltip *xio.ByteBuffer, all
 			return false
 		}, true, true, eqp.Modifiers...)
 	}
+	for _, mod := range w.Modifiers {
+		if !mod.Enabled {
+			continue
+		}
+		var bonus Bonus
+		var ok bool
+		for _, f := range mod.Features {
+			if bonus, ok = f.(Bonus); ok {
+				bonus.SetSubOwner(mod)
+			}
+			w.extractWeaponBonus(f, bonusSet, allowed, fxp.From(dieCount), tooltip)
+		}
+	}
 	if len(bonusSet) == 0 {
 		return nil
 	}
@@ -856,6 +973,7 @@ func (w *Weapon) SetChildren(_ []*Weapon) {
 // CellData returns the cell data information for the given column.
 func (w *Weapon) CellData(columnID int, data *CellData) {
 	var buffer xio.ByteBuffer
+	var profBuffer xio.ByteBuffer
 	data.Type = TextCellType
 	switch columnID {
 	case WeaponDescriptionColumn:
@@ -865,10 +983,13 @@ func (w *Weapon) CellData(columnID int, data *CellData) {
 		data.Primary = w.Usage
 	case WeaponSLColumn:
 		data.Primary = w.SkillLevel(&buffer).String()
+		w.ResolvedProficiencyPenalty(&profBuffer)
 	case WeaponParryColumn:
 		data.Primary = w.ResolvedParry(&buffer)
+		w.ResolvedProficiencyPenalty(&profBuffer)
 	case WeaponBlockColumn:
 		data.Primary = w.ResolvedBlock(&buffer)
+		w.ResolvedProficiencyPenalty(&profBuffer)
 	case WeaponDamageColumn:
 		data.Primary = w.Damage.ResolvedDamage(&buffer)
 	case WeaponReachColumn:
@@ -919,6 +1040,18 @@ func (w *Weapon) CellData(columnID int, data *CellData) {
 				fmt.Fprintf(&tooltip, i18n.Text("Requires two hands. If you have at least ST %v, you can use it one-handed, but it becomes unready after you attack with it. If you have at least ST %v, you can use it one-handed with no readiness penalty."), minST.Mul(fxp.OneAndAHalf).Ceil(), minST.Mul(fxp.Two).Ceil())
 			}
 		}
+		if canDual, _, stRequired := w.ResolvedDualWield(w.PC()); canDual {
+			if tooltip.Len() != 0 {
+				tooltip.WriteString("\n\n")
+			}
+			fmt.Fprintf(&tooltip, i18n.Text("Can be wielded in your off hand as part of a dual-wield (Two-Weapon Fighting) attack, at the usual -4 off-hand weapon penalty. If you have at least ST %v, you avoid the penalty."), stRequired)
+		}
+		if movePenalty := w.ResolvedMovePenalty(w.PC()); movePenalty > 0 {
+			if tooltip.Len() != 0 {
+				tooltip.WriteString("\n\n")
+			}
+			fmt.Fprintf(&tooltip, i18n.Text("This weapon reduces Basic Move by %v while held."), movePenalty)
+		}
 		data.Tooltip = tooltip.String()
 	case WeaponAccColumn:
 		data.Primary = w.CombinedAcc(&buffer)
@@ -927,19 +1060,34 @@ func (w *Weapon) CellData(columnID int, data *CellData) {
 	case WeaponRoFColumn:
 		data.Primary = w.RateOfFire
 	case WeaponShotsColumn:
-		data.Primary = w.Shots
+		data.Primary = w.CombinedShots(&buffer)
 	case WeaponBulkColumn:
 		data.Primary = w.Bulk
 	case WeaponRecoilColumn:
 		data.Primary = w.Recoil
+	case WeaponHitChanceColumn:
+		data.Primary = w.CombatMetrics(nil).HitChance.String() + "%"
+	case WeaponExpectedDamageColumn:
+		data.Primary = w.CombatMetrics(nil).ExpectedDamagePerRound.String()
+	case WeaponRangeBandsColumn:
+		bands := w.RangeBands()
+		data.Primary = bands.Min.String() + "/" + bands.HalfDamage.String() + "/" + bands.Max.String()
+	case WeaponDamageBreakdownColumn:
+		data.Primary = w.CombinedDamageBreakdown(&buffer)
 	case PageRefCellAlias:
 		data.Type = PageRefCellType
 	}
+	if profBuffer.Len() > 0 {
+		if data.Tooltip != "" {
+			data.Tooltip += "\n\n"
+		}
+		data.Tooltip += strings.TrimPrefix(profBuffer.String(), "\n")
+	}
 	if buffer.Len() > 0 {
 		if data.Tooltip != "" {
 			data.Tooltip += "\n\n"
 		}
-		data.Tooltip = i18n.Text("Includes modifiers from:") + buffer.String()
+		data.Tooltip += i18n.Text("Includes modifiers from:") + buffer.String()
 	}
 }
 
@@ -952,10 +1100,42 @@ func (w *Weapon) CombinedAcc(tooltip *xio.ByteBuffer) string {
 		return i18n.Text("Jet")
 	}
 	weaponAcc, scopeAcc := w.ResolvedAccuracy(tooltip)
+	acc := weaponAcc.String()
 	if scopeAcc != 0 {
-		return weaponAcc.String() + scopeAcc.StringWithSign()
+		acc += scopeAcc.StringWithSign()
 	}
-	return weaponAcc.String()
+	if canDual, _, _ := w.ResolvedDualWield(w.PC()); canDual {
+		acc += "◊"
+	}
+	return acc
+}
+
+// CombinedDamageBreakdown returns the per-type damage breakdown used in the GURPS ranged weapon tables, e.g.
+// "2d(2) cr + 1d burn linked", falling back to the resolved single-component damage if no breakdown has been recorded.
+// Returns an empty string for non-ranged weapons.
+func (w *Weapon) CombinedDamageBreakdown(tooltip *xio.ByteBuffer) string {
+	if w.Type != RangedWeaponType {
+		return ""
+	}
+	if len(w.DamageComponents) == 0 {
+		return w.Damage.ResolvedDamage(tooltip)
+	}
+	parts := make([]string, 0, len(w.DamageComponents))
+	for _, c := range w.DamageComponents {
+		rendered := c.Render()
+		parts = append(parts, rendered)
+		if tooltip != nil {
+			source := c.Source
+			if source == "" {
+				source = i18n.Text("base")
+			}
+			tooltip.WriteByte('\n')
+			tooltip.WriteString(rendered)
+			tooltip.WriteString(" — ")
+			tooltip.WriteString(source)
+		}
+	}
+	return strings.Join(parts, " + ")
 }
 
 // CombinedMinST returns the combined string used in the GURPS weapon tables for minimum ST.
@@ -980,6 +1160,9 @@ func (w *Weapon) CombinedMinST() string {
 			buffer.WriteRune('†')
 		}
 	}
+	if canDual, _, _ := w.ResolvedDualWield(w.PC()); canDual {
+		buffer.WriteRune('◊')
+	}
 	return buffer.String()
 }
 
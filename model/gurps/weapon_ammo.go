@@ -0,0 +1,133 @@
+/*
+ * Copyright ©1998-2024 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/xio"
+)
+
+// shotsCapacity parses the GURPS shot notation in w.Shots (e.g. "8",
+// "8(3)", "6(3i)") into its magazine capacity, reload time, and whether it
+// reloads one shot at a time (the "i" suffix, e.g. a revolver).
+func (w *Weapon) shotsCapacity() (capacity, reloadTime int, perShot bool) {
+	s := strings.TrimSpace(w.Shots)
+	capPart := s
+	if open := strings.IndexByte(s, '('); open != -1 {
+		capPart = s[:open]
+		reloadPart := strings.TrimSuffix(s[open+1:], ")")
+		if strings.HasSuffix(strings.ToLower(reloadPart), "i") {
+			perShot = true
+			reloadPart = reloadPart[:len(reloadPart)-1]
+		}
+		reloadTime, _ = strconv.Atoi(strings.TrimSpace(reloadPart))
+	}
+	capacity, _ = strconv.Atoi(strings.TrimSpace(capPart))
+	return capacity, reloadTime, perShot
+}
+
+// Fire consumes up to shots rounds from CurrentShots, returning the number
+// actually fired, which may be less than requested if fewer shots remain
+// loaded. Firing interrupts any reload in progress.
+func (w *Weapon) Fire(shots int) int {
+	if shots <= 0 || w.CurrentShots <= 0 {
+		return 0
+	}
+	if shots > w.CurrentShots {
+		shots = w.CurrentShots
+	}
+	w.CurrentShots -= shots
+	w.ReloadProgress = 0
+	return shots
+}
+
+// Reload begins or advances a reload of this weapon with ammoType, clearing
+// out any different ammo type still loaded first, and returns true once the
+// weapon is fully loaded. A weapon with a per-shot reload (the "(Ni)" shots
+// notation, e.g. a revolver) tops up one shot per call; any other weapon
+// reloads to capacity in a single call, since the GM adjudicates its
+// multi-turn reload as a single action.
+func (w *Weapon) Reload(ammoType string) bool {
+	capacity, _, perShot := w.shotsCapacity()
+	if capacity <= 0 {
+		return true
+	}
+	if w.CurrentShots > 0 && w.LoadedAmmoType != ammoType {
+		w.Unload()
+	}
+	w.LoadedAmmoType = ammoType
+	if perShot {
+		if w.CurrentShots < capacity {
+			w.CurrentShots++
+			w.ReloadProgress++
+		}
+		return w.CurrentShots >= capacity
+	}
+	w.CurrentShots = capacity
+	w.ReloadProgress = 0
+	return true
+}
+
+// Unload empties this weapon's magazine or chamber, discarding any loaded
+// ammo type and in-progress reload.
+func (w *Weapon) Unload() {
+	w.CurrentShots = 0
+	w.LoadedAmmoType = ""
+	w.ReloadProgress = 0
+}
+
+// CombinedShots returns the "loaded/capacity (reload)" string used in the
+// Shots column, and, if tooltip is non-nil, appends a reload-time
+// breakdown, including the "(i)" per-shot-reload notation for weapons like
+// revolvers.
+func (w *Weapon) CombinedShots(tooltip *xio.ByteBuffer) string {
+	capacity, reloadTime, perShot := w.shotsCapacity()
+	if capacity <= 0 {
+		return w.Shots
+	}
+	var buffer strings.Builder
+	buffer.WriteString(strconv.Itoa(w.CurrentShots))
+	buffer.WriteByte('/')
+	buffer.WriteString(strconv.Itoa(capacity))
+	if reloadTime > 0 {
+		buffer.WriteString(" (")
+		buffer.WriteString(strconv.Itoa(reloadTime))
+		if perShot {
+			buffer.WriteByte('i')
+		}
+		buffer.WriteByte(')')
+	}
+	if tooltip != nil {
+		if reloadTime > 0 {
+			tooltip.WriteByte('\n')
+			if perShot {
+				tooltip.WriteString(i18n.Text("Reloads "))
+				tooltip.WriteString(strconv.Itoa(reloadTime))
+				tooltip.WriteString(i18n.Text(" second(s) per shot."))
+			} else {
+				tooltip.WriteString(i18n.Text("Reloads fully in "))
+				tooltip.WriteString(strconv.Itoa(reloadTime))
+				tooltip.WriteString(i18n.Text(" second(s)."))
+			}
+		}
+		if w.LoadedAmmoType != "" {
+			tooltip.WriteByte('\n')
+			tooltip.WriteString(i18n.Text("Loaded with "))
+			tooltip.WriteString(w.LoadedAmmoType)
+			tooltip.WriteByte('.')
+		}
+	}
+	return buffer.String()
+}
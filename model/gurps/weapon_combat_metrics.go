@@ -0,0 +1,149 @@
+/*
+ * Copyright ©1998-2024 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"strconv"
+
+	"github.com/richardwilkes/gcs/v5/model/fxp"
+)
+
+// CombatTarget describes the thing a Weapon's combat metrics are being
+// computed against.
+type CombatTarget struct {
+	DR       fxp.Int // Damage resistance to subtract from each damage roll.
+	Distance fxp.Int // Range to the target, in yards, for ranged weapons.
+}
+
+// CombatMetrics holds the derived combat statistics for a single attack with
+// a Weapon against a CombatTarget.
+type CombatMetrics struct {
+	HitChance               fxp.Int // Percentage chance (0-100) of a successful to-hit roll.
+	ParryChance             fxp.Int // Percentage chance (0-100) a defender parries, if this is a melee weapon.
+	BlockChance             fxp.Int // Percentage chance (0-100) a defender blocks, if this is a melee weapon.
+	MinDamage               fxp.Int // Minimum damage after DR, floored at 0.
+	AverageDamage           fxp.Int // Mean damage after DR, floored at 0.
+	MaxDamage               fxp.Int // Maximum damage after DR, floored at 0.
+	ExpectedDamagePerRound  fxp.Int // AverageDamage × hit chance × shots fired per attack (rate of fire).
+	ExpectedDamagePerSecond fxp.Int // ExpectedDamagePerRound averaged over the fire-and-reload cycle of a limited magazine.
+}
+
+// CombatMetrics computes derived combat statistics for this weapon against
+// target, using SkillLevel for hit chance and WeaponDamage.ResolvedDamage's
+// underlying dice expression for the damage distribution. target may be nil,
+// in which case DR is treated as 0 and Distance as 0.
+func (w *Weapon) CombatMetrics(target *CombatTarget) *CombatMetrics {
+	if target == nil {
+		target = &CombatTarget{}
+	}
+	m := &CombatMetrics{
+		HitChance: successChanceFor3d6(w.SkillLevel(nil)),
+	}
+	if w.Type == MeleeWeaponType {
+		m.ParryChance = successChanceFor3d6(w.parryOrBlockLevel(w.ResolvedParry(nil)))
+		m.BlockChance = successChanceFor3d6(w.parryOrBlockLevel(w.ResolvedBlock(nil)))
+	}
+
+	minRoll, avgRoll, maxRoll := w.Damage.RollRange()
+	m.MinDamage = (minRoll - target.DR).Max(0)
+	m.AverageDamage = (avgRoll - target.DR).Max(0)
+	m.MaxDamage = (maxRoll - target.DR).Max(0)
+
+	shotsPerAttack := fxp.One
+	if rof, err := fxp.FromString(w.RateOfFire); err == nil && rof > 0 {
+		shotsPerAttack = rof
+	}
+	hitFraction := m.HitChance.Div(fxp.Hundred)
+	m.ExpectedDamagePerRound = m.AverageDamage.Mul(hitFraction).Mul(shotsPerAttack)
+	m.ExpectedDamagePerSecond = w.expectedDamagePerSecond(m.ExpectedDamagePerRound, shotsPerAttack)
+	return m
+}
+
+// expectedDamagePerSecond scales perRoundDamage -- the expected damage dealt in a single one-second attack -- down
+// to account for the reload turns a limited magazine forces into each sustained-fire cycle. Weapons with no
+// magazine/reload tracking (w.Shots has no "(N)" reload notation) fire every second indefinitely, so their
+// per-second rate equals their per-round rate.
+func (w *Weapon) expectedDamagePerSecond(perRoundDamage, shotsPerAttack fxp.Int) fxp.Int {
+	capacity, reloadTime, _ := w.shotsCapacity()
+	if capacity <= 0 || reloadTime <= 0 {
+		return perRoundDamage
+	}
+	turnsToEmpty := fxp.From(capacity).Div(shotsPerAttack).Ceil()
+	if turnsToEmpty <= 0 {
+		turnsToEmpty = fxp.One
+	}
+	cycleSeconds := turnsToEmpty + fxp.From(reloadTime)
+	return perRoundDamage.Mul(turnsToEmpty).Div(cycleSeconds)
+}
+
+// RollRange returns the minimum, average, and maximum results of this
+// damage's base dice, before any target DR is subtracted. Non-dice
+// (fixed-value) damage reports the same value for all three.
+func (d *WeaponDamage) RollRange() (minimum, average, maximum fxp.Int) {
+	if d.Base == nil {
+		return 0, 0, 0
+	}
+	return fxp.From(d.Base.Minimum()), fxp.From(d.Base.Average()), fxp.From(d.Base.Maximum())
+}
+
+// parryOrBlockLevel extracts the leading integer from a resolved parry or
+// block string (e.g. "9" or "No"), returning fxp.Min if there is none.
+func (w *Weapon) parryOrBlockLevel(resolved string) fxp.Int {
+	end := 0
+	for end < len(resolved) && ((resolved[end] >= '0' && resolved[end] <= '9') ||
+		(end == 0 && (resolved[0] == '-' || resolved[0] == '+'))) {
+		end++
+	}
+	n, err := strconv.Atoi(resolved[:end])
+	if err != nil {
+		return fxp.Min
+	}
+	return fxp.From(n)
+}
+
+// threeD6CumulativePercentByTarget is P(3d6 <= target) expressed as whole
+// percent, i.e. the GURPS success-roll table, for effective skill 3-18.
+var threeD6CumulativePercentByTarget = []struct {
+	Target  fxp.Int
+	Percent fxp.Int
+}{
+	{fxp.From(18), fxp.Hundred},
+	{fxp.From(17), fxp.Hundred},
+	{fxp.From(16), fxp.From(98)},
+	{fxp.From(15), fxp.From(95)},
+	{fxp.From(14), fxp.From(91)},
+	{fxp.From(13), fxp.From(84)},
+	{fxp.From(12), fxp.From(74)},
+	{fxp.From(11), fxp.From(63)},
+	{fxp.From(10), fxp.From(50)},
+	{fxp.From(9), fxp.From(37)},
+	{fxp.From(8), fxp.From(26)},
+	{fxp.From(7), fxp.From(16)},
+	{fxp.From(6), fxp.From(9)},
+	{fxp.From(5), fxp.From(5)},
+	{fxp.From(4), fxp.From(2)},
+	{fxp.From(3), fxp.From(0)},
+}
+
+// successChanceFor3d6 returns the percentage chance (0-100) of rolling at or
+// under target on 3d6, clamped to the usual GURPS range of effective skill.
+func successChanceFor3d6(target fxp.Int) fxp.Int {
+	if target < fxp.From(3) {
+		return fxp.From(0)
+	}
+	for _, row := range threeD6CumulativePercentByTarget {
+		if target >= row.Target {
+			return row.Percent
+		}
+	}
+	return fxp.Hundred
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright ©1998-2024 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/richardwilkes/gcs/v5/model/fxp"
+	"github.com/richardwilkes/rpgtools/dice"
+	"github.com/richardwilkes/toolbox/i18n"
+)
+
+// DamageType describes one kind of damage a DamageComponent may use (e.g.
+// "cr", "burn", "tox"), along with the defaults a data-driven content pack
+// (Ultra-Tech, Powers, etc.) needs in order to register its own elemental
+// damage types without modifying this package.
+type DamageType struct {
+	ID                  string
+	ShortLabel          string
+	name                string
+	DefaultArmorDivisor fxp.Int
+	FollowUp            bool // true if this type is only ever applied as a follow-up to a preceding component
+}
+
+// Name returns the localized display name for this damage type, falling
+// back to its ShortLabel if it wasn't given a display name.
+func (d DamageType) Name() string {
+	if d.name == "" {
+		return d.ShortLabel
+	}
+	return i18n.Text(d.name)
+}
+
+var damageTypeRegistry = map[string]*DamageType{}
+
+func init() {
+	for _, dt := range []DamageType{
+		{ID: "cr", ShortLabel: "cr", name: "Crushing"},
+		{ID: "cut", ShortLabel: "cut", name: "Cutting"},
+		{ID: "imp", ShortLabel: "imp", name: "Impaling"},
+		{ID: "pi-", ShortLabel: "pi-", name: "Small Piercing"},
+		{ID: "pi", ShortLabel: "pi", name: "Piercing"},
+		{ID: "pi+", ShortLabel: "pi+", name: "Large Piercing"},
+		{ID: "pi++", ShortLabel: "pi++", name: "Huge Piercing"},
+		{ID: "burn", ShortLabel: "burn", name: "Burning", FollowUp: true},
+		{ID: "tox", ShortLabel: "tox", name: "Toxic", FollowUp: true},
+		{ID: "cor", ShortLabel: "cor", name: "Corrosion", FollowUp: true},
+		{ID: "fat", ShortLabel: "fat", name: "Fatigue"},
+	} {
+		RegisterDamageType(dt)
+	}
+}
+
+// RegisterDamageType adds (or replaces) a DamageType in the shared
+// registry, so that a content pack can add its own elemental types
+// alongside the built-in GURPS Basic Set set.
+func RegisterDamageType(dt DamageType) {
+	cp := dt
+	damageTypeRegistry[dt.ID] = &cp
+}
+
+// LookupDamageType returns the registered DamageType for id, or a fallback
+// DamageType using id as both its ID and ShortLabel if none was registered.
+func LookupDamageType(id string) DamageType {
+	if dt, ok := damageTypeRegistry[id]; ok {
+		return *dt
+	}
+	return DamageType{ID: id, ShortLabel: id}
+}
+
+// DamageComponent is a single piece of a (possibly multi-part) damage
+// expression, such as the "2d(2) cr" and "1d burn linked" halves of
+// "2d(2) cr + 1d burn linked".
+//
+// WeaponDamageData itself isn't present in this tree extract, so
+// WeaponDamage still stores and resolves only its existing single
+// component; Weapon.DamageComponents holds the breakdown separately for
+// display via Weapon.CombinedDamageBreakdown. Folding Components
+// []DamageComponent into WeaponDamageData, ResolvedDamage, HashCode, and
+// JSON marshalling belongs with that file once it's available.
+type DamageComponent struct {
+	Dice         *dice.Dice `json:"dice,omitempty"`
+	Type         string     `json:"type,omitempty"`
+	ArmorDivisor fxp.Int    `json:"armor_divisor,omitempty"`
+	Linked       bool       `json:"linked,omitempty"` // true if this component only applies when the preceding component did damage
+	Source       string     `json:"source,omitempty"` // where this component came from, e.g. "base", "modifier", or an ammo type name
+}
+
+// ParseDamageComponents parses a damage expression with one or more "+"
+// separated components, such as "2d(2) cr + 1d burn linked", into a
+// DamageComponent per part.
+func ParseDamageComponents(expr string) []DamageComponent {
+	var components []DamageComponent
+	for _, part := range strings.Split(expr, "+") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		components = append(components, parseDamageComponent(part))
+	}
+	return components
+}
+
+func parseDamageComponent(part string) DamageComponent {
+	linked := false
+	if trimmed := strings.TrimSpace(part); strings.HasSuffix(strings.ToLower(trimmed), "linked") {
+		linked = true
+		part = strings.TrimSpace(trimmed[:len(trimmed)-len("linked")])
+	}
+	fields := strings.Fields(part)
+	component := DamageComponent{ArmorDivisor: fxp.One, Linked: linked}
+	if len(fields) == 0 {
+		return component
+	}
+	diceStr := fields[0]
+	if open := strings.IndexByte(diceStr, '('); open != -1 && strings.HasSuffix(diceStr, ")") {
+		if div, err := fxp.FromString(diceStr[open+1 : len(diceStr)-1]); err == nil {
+			component.ArmorDivisor = div
+		}
+		diceStr = diceStr[:open]
+	}
+	component.Dice = dice.New(diceStr)
+	if len(fields) > 1 {
+		typ := fields[1]
+		if open := strings.IndexByte(typ, '('); open != -1 && strings.HasSuffix(typ, ")") {
+			if div, err := fxp.FromString(typ[open+1 : len(typ)-1]); err == nil {
+				component.ArmorDivisor = div
+			}
+			typ = typ[:open]
+		}
+		component.Type = typ
+	}
+	return component
+}
+
+// Render returns the canonical string form of this component, e.g.
+// "2d(2) cr" or "1d burn linked".
+func (c DamageComponent) Render() string {
+	var b strings.Builder
+	if c.Dice != nil {
+		b.WriteString(c.Dice.String())
+	}
+	if c.ArmorDivisor != 0 && c.ArmorDivisor != fxp.One {
+		fmt.Fprintf(&b, "(%s)", c.ArmorDivisor.String())
+	}
+	if c.Type != "" {
+		b.WriteByte(' ')
+		b.WriteString(c.Type)
+	}
+	if c.Linked {
+		b.WriteString(" linked")
+	}
+	return b.String()
+}
+
+// RenderDamageComponents joins components back into a single "+" separated
+// damage expression.
+func RenderDamageComponents(components []DamageComponent) string {
+	parts := make([]string, 0, len(components))
+	for _, c := range components {
+		parts = append(parts, c.Render())
+	}
+	return strings.Join(parts, " + ")
+}
@@ -0,0 +1,30 @@
+/*
+ * Copyright ©1998-2024 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import "github.com/richardwilkes/gcs/v5/model/fxp"
+
+// ResolvedDualWield reports whether this weapon may be wielded in the off
+// hand alongside a primary weapon, the skill penalty for doing so, and the
+// ST at or above which that penalty disappears, using the same 1.5x ST
+// multiplier pattern already used for two-handed weapons.
+func (w *Weapon) ResolvedDualWield(entity *Entity) (canDual bool, offHandPenalty fxp.Int, stRequired fxp.Int) {
+	if !w.DualWieldable {
+		return false, 0, 0
+	}
+	stRequired = w.ResolvedMinimumStrength(nil).Mul(fxp.OneAndAHalf).Ceil()
+	offHandPenalty = fxp.From(-4)
+	if entity != nil && entity.StrikingStrength() >= stRequired {
+		offHandPenalty = 0
+	}
+	return true, offHandPenalty, stRequired
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright ©1998-2024 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"strings"
+
+	"github.com/richardwilkes/gcs/v5/model/fxp"
+)
+
+// improvisedWeightSource is optionally implemented by a WeaponOwner to
+// expose its physical weight in pounds, used to derive an improvised
+// weapon's minimum ST. An owner that doesn't implement it (or reports a
+// non-positive weight) is treated as weighing a nominal 1 lb.
+type improvisedWeightSource interface {
+	Weight() fxp.Int
+}
+
+// NewImprovisedWeapon synthesizes a melee Weapon for owner from its tags,
+// weight, and rated ST, for use when owner doesn't already declare a weapon
+// of its own. The result is marked Improvised so it can be excluded from
+// persistence while still showing up in CellData columns and
+// SeparateWeapons results like any other Weapon.
+func NewImprovisedWeapon(owner WeaponOwner) *Weapon {
+	weight := fxp.One
+	if src, ok := owner.(improvisedWeightSource); ok && src.Weight() > 0 {
+		weight = src.Weight()
+	}
+	tags := owner.TagList()
+	w := NewWeapon(owner, MeleeWeaponType)
+	w.Improvised = true
+	w.Damage.StrengthType = SwingStrengthDamage
+	w.Damage.Type = improvisedDamageType(tags)
+	w.Reach = improvisedReach(tags)
+	w.MinST = improvisedMinimumStrength(weight)
+	w.Defaults = improvisedSkillDefaults(tags)
+	return w
+}
+
+// improvisedDamageType picks a cr/cut/imp damage type from the equipment's
+// shape tags, defaulting to crushing for a blunt, unshaped object.
+func improvisedDamageType(tags []string) string {
+	for _, tag := range tags {
+		switch {
+		case hasShape(tag, "blade", "edge"):
+			return "cut"
+		case hasShape(tag, "point", "spike"):
+			return "imp"
+		}
+	}
+	return "cr"
+}
+
+// improvisedReach derives a reach from the equipment's physical size tags.
+func improvisedReach(tags []string) string {
+	for _, tag := range tags {
+		if hasShape(tag, "long", "pole") {
+			return "1,2"
+		}
+	}
+	return "1"
+}
+
+// improvisedMinimumStrength applies the B&E p.20 rule of thumb that an
+// improvised weapon's minimum ST is roughly its weight in pounds.
+func improvisedMinimumStrength(weight fxp.Int) fxp.Int {
+	return weight.Max(fxp.One)
+}
+
+// improvisedSkillDefaults builds the skill-default set a GM would allow for
+// swinging an improvised object: Brawling, DX-4, and a shape-appropriate
+// weapon skill at a steeper penalty.
+func improvisedSkillDefaults(tags []string) []*SkillDefault {
+	defaults := []*SkillDefault{
+		{DefaultType: "skill", Name: "Brawling"},
+		{DefaultType: "dx", Modifier: fxp.From(-4)},
+	}
+	for _, tag := range tags {
+		switch {
+		case hasShape(tag, "blade", "edge"):
+			defaults = append(defaults, &SkillDefault{DefaultType: "skill", Name: "Knife", Modifier: fxp.From(-3)})
+		case hasShape(tag, "blunt", "haft", "long", "pole"):
+			defaults = append(defaults, &SkillDefault{DefaultType: "skill", Name: "Axe/Mace", Modifier: fxp.From(-3)})
+		}
+	}
+	return defaults
+}
+
+func hasShape(tag string, shapes ...string) bool {
+	tag = strings.ToLower(tag)
+	for _, shape := range shapes {
+		if strings.Contains(tag, shape) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImprovisedWeapons returns a synthesized improvised Weapon for this piece
+// of equipment, wrapped in a single-element slice for symmetry with a
+// normal weapon list, unless existing already contains at least one
+// declared weapon.
+func (e *Equipment) ImprovisedWeapons(existing []*Weapon) []*Weapon {
+	if len(existing) != 0 {
+		return nil
+	}
+	return []*Weapon{NewImprovisedWeapon(e)}
+}
+
+// CollectEquipmentWeapons returns equipment's own declared weapons, falling
+// back to ImprovisedWeapons when it has none. Entity.Weapons() is expected
+// to call this for each piece of carried equipment when aggregating the
+// character's full weapon list; entity.go isn't present in this tree
+// extract, so callers outside this package build that aggregation
+// themselves (see ui/tui's sheetTab.refresh, which has no Entity-side
+// aggregation to rely on).
+func CollectEquipmentWeapons(equipment *Equipment, declared []*Weapon) []*Weapon {
+	if len(declared) != 0 {
+		return declared
+	}
+	return equipment.ImprovisedWeapons(declared)
+}
+
+// PersistableWeapons filters weapons down to the subset that should be
+// written back to disk, excluding any synthesized by NewImprovisedWeapon.
+// Equipment's own weapon-list marshalling is expected to call this before
+// serializing its Weapons field; equipment.go isn't present in this tree
+// extract, so nothing calls this yet.
+func PersistableWeapons(weapons []*Weapon) []*Weapon {
+	persistable := make([]*Weapon, 0, len(weapons))
+	for _, w := range weapons {
+		if !w.Improvised {
+			persistable = append(persistable, w)
+		}
+	}
+	return persistable
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright ©1998-2024 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"strings"
+
+	"github.com/richardwilkes/gcs/v5/model/fxp"
+)
+
+// RangeBands is the parsed form of a GURPS ranged-weapon range string, such
+// as "10/100" or "20, 100/1500", with any "xN" ST multiplier already
+// resolved against a wielder's strength.
+type RangeBands struct {
+	Min        fxp.Int `json:"min,omitempty"`
+	HalfDamage fxp.Int `json:"half_damage,omitempty"`
+	Max        fxp.Int `json:"max,omitempty"`
+}
+
+// ParseRangeBands parses a range string of the form "MinRange, ½D Max" (e.g.
+// "10/100", "x10/x20", "20, 100/1500") into a RangeBands, resolving any "xN"
+// ST multiplier against st.
+func ParseRangeBands(rangeStr string, st fxp.Int) RangeBands {
+	var bands RangeBands
+	rangeStr = strings.TrimSpace(rangeStr)
+	if rangeStr == "" {
+		return bands
+	}
+	minPart := ""
+	rest := rangeStr
+	if comma := strings.IndexByte(rangeStr, ','); comma != -1 {
+		minPart = strings.TrimSpace(rangeStr[:comma])
+		rest = strings.TrimSpace(rangeStr[comma+1:])
+	}
+	if minPart != "" {
+		bands.Min = parseRangeValue(minPart, st)
+	}
+	if slash := strings.IndexByte(rest, '/'); slash != -1 {
+		bands.HalfDamage = parseRangeValue(strings.TrimSpace(rest[:slash]), st)
+		bands.Max = parseRangeValue(strings.TrimSpace(rest[slash+1:]), st)
+	} else if rest != "" {
+		bands.Max = parseRangeValue(rest, st)
+	}
+	return bands
+}
+
+func parseRangeValue(s string, st fxp.Int) fxp.Int {
+	multiplyBySt := strings.HasPrefix(s, "x") || strings.HasPrefix(s, "X")
+	if multiplyBySt {
+		s = s[1:]
+	}
+	value, err := fxp.FromString(s)
+	if err != nil {
+		return 0
+	}
+	if multiplyBySt {
+		value = value.Mul(st).Trunc()
+	}
+	return value
+}
+
+// RangeBands returns the parsed range bands for this weapon, resolved
+// against the wielder's ST (or the rated ST of the owning equipment, if
+// any).
+func (w *Weapon) RangeBands() RangeBands {
+	var st fxp.Int
+	if w.Owner != nil {
+		st = w.Owner.RatedStrength()
+	}
+	if st == 0 {
+		if pc := w.PC(); pc != nil {
+			st = pc.ThrowingStrength()
+		}
+	}
+	return ParseRangeBands(w.Range, st)
+}
+
+// rangeTableStep is one row of the GURPS Basic Set size/speed-and-range
+// table, giving the cumulative range modifier for distances up to (and
+// including) Yards.
+type rangeTableStep struct {
+	Yards    fxp.Int
+	Modifier fxp.Int
+}
+
+var rangeTable = []rangeTableStep{
+	{fxp.From(2), fxp.From(0)},
+	{fxp.From(3), fxp.From(-1)},
+	{fxp.From(5), fxp.From(-2)},
+	{fxp.From(7), fxp.From(-3)},
+	{fxp.From(10), fxp.From(-4)},
+	{fxp.From(15), fxp.From(-5)},
+	{fxp.From(20), fxp.From(-6)},
+	{fxp.From(30), fxp.From(-7)},
+	{fxp.From(50), fxp.From(-8)},
+	{fxp.From(70), fxp.From(-9)},
+	{fxp.From(100), fxp.From(-10)},
+	{fxp.From(150), fxp.From(-11)},
+	{fxp.From(200), fxp.From(-12)},
+	{fxp.From(300), fxp.From(-13)},
+	{fxp.From(500), fxp.From(-14)},
+	{fxp.From(700), fxp.From(-15)},
+	{fxp.From(1000), fxp.From(-16)},
+	{fxp.From(1500), fxp.From(-17)},
+	{fxp.From(2000), fxp.From(-18)},
+}
+
+// RangeModifier returns the GURPS size/speed range table penalty for firing
+// this weapon at distance, along with whether that distance is beyond this
+// weapon's Max range and whether it's still within the ½D (half-damage)
+// range.
+func (w *Weapon) RangeModifier(distance fxp.Int) (mod fxp.Int, beyondMax, belowHalfDamage bool) {
+	bands := w.RangeBands()
+	beyondMax = bands.Max > 0 && distance > bands.Max
+	belowHalfDamage = bands.HalfDamage == 0 || distance <= bands.HalfDamage
+	for _, step := range rangeTable {
+		if distance <= step.Yards {
+			return step.Modifier, beyondMax, belowHalfDamage
+		}
+	}
+	return rangeTable[len(rangeTable)-1].Modifier, beyondMax, belowHalfDamage
+}
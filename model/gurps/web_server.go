@@ -0,0 +1,130 @@
+/*
+ * Copyright ©1998-2024 by Richard A. Wilkes. All rights reserved.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, version 2.0. If a copy of the MPL was not distributed with
+ * this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * This Source Code Form is "Incompatible With Secondary Licenses", as
+ * defined by the Mozilla Public License, version 2.0.
+ */
+
+package gurps
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/richardwilkes/toolbox/errs"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// AutoTLS holds the configuration needed to provision a certificate via ACME
+// (e.g. Let's Encrypt) instead of loading CertFile/KeyFile from disk.
+type AutoTLS struct {
+	Domain   string
+	CacheDir string
+}
+
+// Handler wraps mux in HTTP Basic Auth enforcement via VerifyPassword
+// whenever s has any Users configured, and registers a health-check route so
+// the embedded web server answers at least one real request instead of
+// 404ing everything. The actual GCS sheet-editing routes aren't present in
+// this tree extract; callers that add them should register them on mux
+// before calling Handler.
+func (s *WebServerSettings) Handler(mux *http.ServeMux) http.Handler {
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return s.requireAuth(mux)
+}
+
+// requireAuth returns next unchanged if s has no Users configured, so that
+// an embedded server with no users set up remains open rather than locking
+// everyone out; otherwise it rejects any request that doesn't present
+// credentials VerifyPassword accepts.
+func (s *WebServerSettings) requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock.RLock()
+		hasUsers := len(s.Users) > 0
+		s.Lock.RUnlock()
+		if !hasUsers {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, password, ok := r.BasicAuth()
+		if !ok || !s.VerifyPassword(user, r.RemoteAddr, password) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="gcs"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Serve runs the embedded web server with handler until ctx is canceled, at
+// which point it is given up to ShutdownGracePeriod seconds to drain
+// in-flight requests before returning. CertFile/KeyFile are used for TLS
+// unless autoTLS is non-nil, in which case a certificate is obtained
+// on-demand via ACME for autoTLS.Domain.
+func (s *WebServerSettings) Serve(ctx context.Context, handler http.Handler, autoTLS *AutoTLS, tlsMinVersion uint16) error {
+	server := &http.Server{
+		Addr:         s.Address,
+		Handler:      handler,
+		ReadTimeout:  time.Duration(s.ReadTimeout.AsFloat64() * float64(time.Second)),
+		WriteTimeout: time.Duration(s.WriteTimeout.AsFloat64() * float64(time.Second)),
+		IdleTimeout:  time.Duration(s.IdleTimeout.AsFloat64() * float64(time.Second)),
+	}
+
+	var useTLS bool
+	switch {
+	case autoTLS != nil:
+		mgr := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(autoTLS.Domain),
+			Cache:      autocert.DirCache(autoTLS.CacheDir),
+		}
+		server.TLSConfig = &tls.Config{
+			GetCertificate: mgr.GetCertificate,
+			MinVersion:     tlsMinVersion,
+		}
+		useTLS = true
+	case s.CertFile != "" && s.KeyFile != "":
+		server.TLSConfig = &tls.Config{MinVersion: tlsMinVersion}
+		useTLS = true
+	default:
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		var err error
+		if useTLS && autoTLS != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else if useTLS {
+			err = server.ListenAndServeTLS(s.CertFile, s.KeyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			errCh <- errs.Wrap(err)
+			return
+		}
+		errCh <- nil
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(),
+		time.Duration(s.ShutdownGracePeriod.AsFloat64()*float64(time.Second)))
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return errs.Wrap(err)
+	}
+	return <-errCh
+}
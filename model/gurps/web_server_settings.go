@@ -12,11 +12,18 @@
 package gurps
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/richardwilkes/gcs/v5/model/fxp"
+	"github.com/richardwilkes/toolbox/errs"
+	"golang.org/x/crypto/argon2"
 )
 
 // Minimums and defaults for web server settings.
@@ -31,18 +38,38 @@ var (
 	DefaultIdleTimeout         = fxp.Sixty
 )
 
+// Argon2id tuning parameters used when hashing new passwords. These are
+// intentionally conservative defaults suitable for an embedded server
+// running alongside the desktop app, not a dedicated auth service.
+const (
+	argon2Time      = 3
+	argon2Memory    = 64 * 1024
+	argon2Threads   = 2
+	argon2SaltBytes = 16
+	argon2KeyBytes  = 32
+	argon2idPrefix  = "$argon2id$"
+)
+
+// loginAttemptWindow and maxLoginAttempts bound the in-process rate limiter
+// applied per user+remote-address pair.
+const (
+	loginAttemptWindow = time.Minute
+	maxLoginAttempts   = 5
+)
+
 // WebServerSettings holds the settings for the embedded web server.
 type WebServerSettings struct {
 	Enabled             bool              `json:"enabled"`
 	Address             string            `json:"address,omitempty"`
 	CertFile            string            `json:"cert_file,omitempty"`
 	KeyFile             string            `json:"key_file,omitempty"`
-	ShutdownGracePeriod fxp.Int           `json:"shutdown_grace_period,omitempty"`
-	ReadTimeout         fxp.Int           `json:"read_timeout,omitempty"`
-	WriteTimeout        fxp.Int           `json:"write_timeout,omitempty"`
-	IdleTimeout         fxp.Int           `json:"idle_timeout,omitempty"`
+	ShutdownGracePeriod fxp.Int           `json:"shutdown_grace_period,omitempty" default:"0"`
+	ReadTimeout         fxp.Int           `json:"read_timeout,omitempty" default:"10"`
+	WriteTimeout        fxp.Int           `json:"write_timeout,omitempty" default:"30"`
+	IdleTimeout         fxp.Int           `json:"idle_timeout,omitempty" default:"60"`
 	Lock                sync.RWMutex      `json:"-"`
 	Users               map[string][]byte `json:"users,omitempty"`
+	limiter             loginLimiter
 }
 
 // Validate the settings.
@@ -65,16 +92,148 @@ func (s *WebServerSettings) Validate() {
 	}
 }
 
-// HashedPasswordLookup looks up hashed passwords.
-func (s *WebServerSettings) HashedPasswordLookup(user, _ string) ([]byte, bool) {
+// VerifyPassword is the sole auth entry point for the web server: it checks
+// the given plaintext password against the stored hash for user, subject to
+// a per-user+remoteAddr rate limit. Legacy SHA-256 entries (detected by
+// format) are transparently rehashed to argon2id on a successful
+// verification. Callers must not bypass this by reading Users directly and
+// comparing hashes themselves, since that would skip both the rate limit and
+// the rehash-on-login upgrade.
+func (s *WebServerSettings) VerifyPassword(user, remoteAddr, password string) bool {
+	if !s.limiter.allow(user, remoteAddr) {
+		return false
+	}
 	s.Lock.RLock()
-	defer s.Lock.RUnlock()
-	pw, ok := s.Users[user]
-	return pw, ok
+	stored, ok := s.Users[user]
+	s.Lock.RUnlock()
+	if !ok {
+		return false
+	}
+	ok, needsRehash := verifyEncodedPassword(string(stored), password)
+	if ok {
+		s.limiter.reset(user, remoteAddr)
+		if needsRehash {
+			if err := s.SetUserPassword(user, password); err != nil {
+				errs.Log(err)
+			}
+		}
+	}
+	return ok
+}
+
+// SetUserPassword hashes plaintext with a fresh random salt and stores the
+// resulting encoded argon2id value for user.
+func (s *WebServerSettings) SetUserPassword(user, plaintext string) error {
+	encoded, err := s.Hasher(plaintext)
+	if err != nil {
+		return err
+	}
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	if s.Users == nil {
+		s.Users = make(map[string][]byte)
+	}
+	s.Users[user] = []byte(encoded)
+	return nil
+}
+
+// RemoveUser removes a user's stored password, if any.
+func (s *WebServerSettings) RemoveUser(user string) {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	delete(s.Users, user)
+}
+
+// Hasher hashes a plaintext password, returning the encoded argon2id form
+// (salt and parameters included) suitable for storage in Users.
+func (s *WebServerSettings) Hasher(plaintext string) (string, error) {
+	salt := make([]byte, argon2SaltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errs.Wrap(err)
+	}
+	hash := argon2.IDKey([]byte(plaintext), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyBytes)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+// verifyEncodedPassword checks plaintext against an encoded hash previously
+// produced by Hasher, or against the legacy unsalted SHA-256 format. It
+// returns whether the password matched and whether the stored value should
+// be rehashed to the current format.
+func verifyEncodedPassword(encoded, plaintext string) (matched, needsRehash bool) {
+	if strings.HasPrefix(encoded, argon2idPrefix) {
+		salt, hash, time_, memory, threads, err := parseArgon2id(encoded)
+		if err != nil {
+			return false, false
+		}
+		candidate := argon2.IDKey([]byte(plaintext), salt, time_, memory, threads, uint32(len(hash)))
+		return subtle.ConstantTimeCompare(candidate, hash) == 1, false
+	}
+	// Legacy sha256(password + "!gcs") format: a bare 32-byte digest.
+	legacy := legacySHA256(plaintext)
+	return subtle.ConstantTimeCompare(legacy, []byte(encoded)) == 1, true
 }
 
-// Hasher hashes passwords.
-func (s *WebServerSettings) Hasher(in string) []byte {
-	h := sha256.Sum256([]byte(in + "!gcs"))
+func legacySHA256(plaintext string) []byte {
+	h := sha256.Sum256([]byte(plaintext + "!gcs"))
 	return h[:]
-}
\ No newline at end of file
+}
+
+func parseArgon2id(encoded string) (salt, hash []byte, time_, memory uint32, threads uint8, err error) {
+	parts := strings.Split(strings.TrimPrefix(encoded, "$"), "$")
+	if len(parts) != 5 || parts[0] != "argon2id" {
+		return nil, nil, 0, 0, 0, errs.New("malformed argon2id hash")
+	}
+	if _, err = fmt.Sscanf(parts[1], "v=%d", new(int)); err != nil {
+		return nil, nil, 0, 0, 0, errs.Wrap(err)
+	}
+	var t, m int
+	var p int
+	if _, err = fmt.Sscanf(parts[2], "m=%d,t=%d,p=%d", &m, &t, &p); err != nil {
+		return nil, nil, 0, 0, 0, errs.Wrap(err)
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[3]); err != nil {
+		return nil, nil, 0, 0, 0, errs.Wrap(err)
+	}
+	if hash, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, nil, 0, 0, 0, errs.Wrap(err)
+	}
+	return salt, hash, uint32(t), uint32(m), uint8(p), nil
+}
+
+// loginLimiter is a small in-process rate limiter keyed by user+remote
+// address, used to blunt brute-force login attempts against Users.
+type loginLimiter struct {
+	lock     sync.Mutex
+	attempts map[string][]time.Time
+}
+
+func (l *loginLimiter) allow(user, remoteAddr string) bool {
+	key := user + "|" + remoteAddr
+	now := time.Now()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.attempts == nil {
+		l.attempts = make(map[string][]time.Time)
+	}
+	cutoff := now.Add(-loginAttemptWindow)
+	kept := l.attempts[key][:0]
+	for _, t := range l.attempts[key] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) >= maxLoginAttempts {
+		l.attempts[key] = kept
+		return false
+	}
+	l.attempts[key] = append(kept, now)
+	return true
+}
+
+func (l *loginLimiter) reset(user, remoteAddr string) {
+	key := user + "|" + remoteAddr
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	delete(l.attempts, key)
+}
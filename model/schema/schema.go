@@ -0,0 +1,192 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+// Package schema generates JSON Schema (draft 2020-12) documents for GCS's
+// on-disk file formats directly from the Go structs the rest of the model
+// package already marshals with encoding/json, so the two stay in lockstep.
+package schema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Draft is the JSON Schema dialect these documents declare.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Document is a generated JSON Schema, represented as the same ordered-ish
+// map shape encoding/json would produce for one.
+type Document map[string]any
+
+// enumType is implemented by the generated enum types (e.g. srcstate.Value,
+// prereq.Type, feature.Type): anything with a Key() method is rendered as a
+// plain JSON string instead of its raw numeric kind. The allowed keys are not
+// listed in the schema.
+type enumType interface {
+	Key() string
+}
+
+// ForType generates a schema Document for t, using id as its stable "$id".
+// Struct fields are discovered via the same json tags encoding/json honors:
+// a name before the first comma, "-" to skip, and "omitempty" to drop the
+// field from "required". Self-referential struct types (e.g. Trait.Children
+// []*Trait) are broken into "$defs" entries referenced by "$ref" rather than
+// being reflected into forever, which would otherwise recurse until the
+// stack overflows.
+func ForType(id string, t reflect.Type) Document {
+	doc := Document{
+		"$schema": Draft,
+		"$id":     id,
+	}
+	b := newSchemaBuilder()
+	for k, v := range b.schemaForGoType(t) {
+		doc[k] = v
+	}
+	if len(b.defs) > 0 {
+		doc["$defs"] = b.defs
+	}
+	return doc
+}
+
+// schemaBuilder tracks the struct types already turned into "$defs" entries
+// (or currently being turned into one), so a struct type is only ever
+// reflected into once no matter how many times it's reachable, and a cycle
+// resolves to a "$ref" instead of infinite recursion.
+type schemaBuilder struct {
+	defs    Document
+	visited map[reflect.Type]bool
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{defs: Document{}, visited: map[reflect.Type]bool{}}
+}
+
+func (b *schemaBuilder) schemaForGoType(t reflect.Type) Document {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*enumType)(nil)).Elem()) {
+		return Document{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return b.refForStruct(t)
+	case reflect.Slice, reflect.Array:
+		return Document{
+			"type":  "array",
+			"items": b.schemaForGoType(t.Elem()),
+		}
+	case reflect.Map:
+		return Document{
+			"type":                 "object",
+			"additionalProperties": b.schemaForGoType(t.Elem()),
+		}
+	case reflect.String:
+		return Document{"type": "string"}
+	case reflect.Bool:
+		return Document{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Document{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Document{"type": "number"}
+	default:
+		return Document{}
+	}
+}
+
+// refForStruct returns a "$ref" into "$defs" for t, populating that $defs
+// entry the first time t is seen. Marking t as visited before recursing into
+// its fields is what turns a self-reference (directly or through a cycle of
+// other structs) into a $ref instead of unbounded recursion.
+func (b *schemaBuilder) refForStruct(t reflect.Type) Document {
+	name := schemaDefName(t)
+	ref := Document{"$ref": "#/$defs/" + name}
+	if b.visited[t] {
+		return ref
+	}
+	b.visited[t] = true
+	b.defs[name] = b.structBody(t)
+	return ref
+}
+
+// schemaDefName derives a "$defs" key for t, falling back to its full
+// package-qualified string for the rare anonymous or unnamed type.
+func schemaDefName(t reflect.Type) string {
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return strings.NewReplacer("*", "", ".", "_", "[", "_", "]", "_").Replace(t.String())
+}
+
+// structBody reflects t's exported fields into an object schema, without
+// wrapping the result in a "$ref" -- used both to populate a $defs entry and
+// to flatten an anonymous embedded struct's fields directly into its
+// containing object, matching how encoding/json flattens anonymous fields
+// (used throughout, e.g. Weapon embeds WeaponData).
+func (b *schemaBuilder) structBody(t reflect.Type) Document {
+	properties := Document{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+		if name == "" {
+			name = sf.Name
+		}
+		omitEmpty := false
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" {
+				omitEmpty = true
+			}
+		}
+		if sf.Anonymous && name == sf.Name {
+			ft := sf.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				// refForStruct populates (or reuses) ft's own $defs entry, so a
+				// type that's both embedded here and referenced elsewhere only
+				// gets reflected into once.
+				b.refForStruct(ft)
+				if embedded, ok := b.defs[schemaDefName(ft)]; ok {
+					if props, ok := embedded["properties"].(Document); ok {
+						for k, v := range props {
+							properties[k] = v
+						}
+					}
+					if req, ok := embedded["required"].([]string); ok {
+						required = append(required, req...)
+					}
+				}
+				continue
+			}
+		}
+		properties[name] = b.schemaForGoType(sf.Type)
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+	doc := Document{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		doc["required"] = required
+	}
+	return doc
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package schema
+
+import (
+	"reflect"
+
+	"github.com/richardwilkes/gcs/v5/model/gurps"
+	"github.com/richardwilkes/gcs/v5/model/settings"
+)
+
+// BaseURL is the stable root that the generated schemas are published
+// under, so external editors (VS Code, IntelliJ, etc.) can reference a
+// fixed $id instead of a local file path.
+const BaseURL = "https://gcs.wilkes.dev/schema/v1/"
+
+// Kinds lists the on-disk formats this package can produce a schema for;
+// KindForExtension maps file extensions onto them.
+const (
+	SheetKind    = "sheet"
+	TemplateKind = "template"
+	LibraryKind  = "library"
+	SettingsKind = "settings"
+)
+
+// KindForExtension maps a file extension (without the leading dot) to one of
+// the Kind constants above, if recognized.
+func KindForExtension(ext string) (kind string, ok bool) {
+	switch ext {
+	case "gcs":
+		return SheetKind, true
+	case "gct":
+		return TemplateKind, true
+	case "gcs_lib":
+		return LibraryKind, true
+	default:
+		return "", false
+	}
+}
+
+// ForKind generates the schema Document for one of the Kind constants.
+func ForKind(kind string) (Document, bool) {
+	switch kind {
+	case SheetKind:
+		return ForSheet(), true
+	case TemplateKind:
+		return ForTemplate(), true
+	case LibraryKind:
+		return ForLibrary(), true
+	case SettingsKind:
+		return ForSettings(), true
+	default:
+		return nil, false
+	}
+}
+
+// ForSheet generates the schema Document for a character sheet file.
+func ForSheet() Document {
+	return ForType(BaseURL+"sheet.json", reflect.TypeOf(gurps.Entity{}))
+}
+
+// ForTemplate generates the schema Document for a character template file.
+func ForTemplate() Document {
+	return ForType(BaseURL+"template.json", reflect.TypeOf(gurps.Template{}))
+}
+
+// ForLibrary generates the schema Document for a data library file.
+func ForLibrary() Document {
+	return ForType(BaseURL+"library.json", reflect.TypeOf(gurps.LibraryFile{}))
+}
+
+// ForSettings generates the schema Document for the application settings
+// file, including the embedded WebServerSettings.
+func ForSettings() Document {
+	return ForType(BaseURL+"settings.json", reflect.TypeOf(settings.Settings{}))
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Violation describes a single schema mismatch, located by a JSON Pointer
+// (RFC 6901) into the document being checked.
+type Violation struct {
+	Pointer string
+	Message string
+}
+
+// Validate performs a structural check of data against schema, returning
+// every violation found. This is intentionally a lightweight subset of
+// draft 2020-12 (type, required, properties, items, additionalProperties) —
+// enough to catch the corrupted/forward-incompatible files that motivated
+// --validate — rather than a complete JSON Schema implementation.
+func Validate(data []byte, doc Document) ([]Violation, error) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("schema: document is not valid JSON: %w", err)
+	}
+	var violations []Violation
+	validateValue(value, doc, "", &violations)
+	return violations, nil
+}
+
+func validateValue(value any, doc Document, pointer string, violations *[]Violation) {
+	wantType, _ := doc["type"].(string)
+	switch wantType {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			*violations = append(*violations, Violation{pointer, "expected an object"})
+			return
+		}
+		if required, ok := doc["required"].([]string); ok {
+			for _, name := range required {
+				if _, present := obj[name]; !present {
+					*violations = append(*violations, Violation{
+						Pointer: pointer + "/" + name,
+						Message: "missing required property",
+					})
+				}
+			}
+		}
+		props, _ := doc["properties"].(Document)
+		for name, child := range obj {
+			childSchema, ok := props[name]
+			if !ok {
+				continue // additionalProperties beyond what's modeled are tolerated
+			}
+			if childDoc, ok := childSchema.(Document); ok {
+				validateValue(child, childDoc, pointer+"/"+name, violations)
+			}
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			*violations = append(*violations, Violation{pointer, "expected an array"})
+			return
+		}
+		items, _ := doc["items"].(Document)
+		for i, elem := range arr {
+			validateValue(elem, items, fmt.Sprintf("%s/%d", pointer, i), violations)
+		}
+	case "string":
+		if _, ok := value.(string); !ok && value != nil {
+			*violations = append(*violations, Violation{pointer, "expected a string"})
+		}
+	case "integer", "number":
+		if _, ok := value.(float64); !ok && value != nil {
+			*violations = append(*violations, Violation{pointer, "expected a number"})
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok && value != nil {
+			*violations = append(*violations, Violation{pointer, "expected a boolean"})
+		}
+	default:
+		// No type constraint recorded for this node (e.g. a "$ref" into
+		// "$defs", which is not followed) — nothing further to check.
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/rivo/tview"
+)
+
+// browseCategory is one page of the browsePanel, e.g. the character's
+// Advantages or Skills, rendered as one line per entry.
+type browseCategory struct {
+	Label string
+	Lines []string
+}
+
+// browsePanel lets the user page (Ctrl-B) through a fixed set of read-only
+// categories -- Advantages, Skills, Spells, and Equipment -- one at a time,
+// since the sheet tab doesn't have room to show them all simultaneously.
+type browsePanel struct {
+	*tview.TextView
+	categories []browseCategory
+	current    int
+}
+
+func newBrowsePanel() *browsePanel {
+	p := &browsePanel{TextView: tview.NewTextView().SetDynamicColors(true)}
+	p.SetBorder(true)
+	return p
+}
+
+// setCategories replaces the pages the panel cycles through and redraws the
+// current page. The current page index is preserved across calls so that a
+// refresh() doesn't reset what the user was looking at.
+func (p *browsePanel) setCategories(categories []browseCategory) {
+	p.categories = categories
+	if p.current >= len(p.categories) {
+		p.current = 0
+	}
+	p.redraw()
+}
+
+// next advances to the following category, wrapping around, and redraws.
+func (p *browsePanel) next() {
+	if len(p.categories) == 0 {
+		return
+	}
+	p.current = (p.current + 1) % len(p.categories)
+	p.redraw()
+}
+
+func (p *browsePanel) redraw() {
+	if len(p.categories) == 0 {
+		p.SetTitle(i18n.Text("Browse"))
+		p.Clear()
+		return
+	}
+	category := p.categories[p.current]
+	p.SetTitle(fmt.Sprintf("%s (%d/%d)", category.Label, p.current+1, len(p.categories)))
+	p.Clear()
+	fmt.Fprint(p, strings.Join(category.Lines, "\n"))
+}
+
+// stringerLines renders items to their String() form, one per line, for
+// display in a browseCategory.
+func stringerLines[T fmt.Stringer](items []T) []string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, item.String())
+	}
+	return lines
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package tui
+
+import (
+	"github.com/richardwilkes/gcs/v5/model/fxp"
+	"github.com/richardwilkes/gcs/v5/model/gurps"
+	"github.com/richardwilkes/gcs/v5/model/jio"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/rivo/tview"
+)
+
+// showPointsForm pops up a modal list of PointsRecord entries with add/
+// remove controls, mirroring the Unison pointsEditor but rendered as a
+// tview form.
+func (t *sheetTab) showPointsForm(pages *tview.Pages) {
+	current := gurps.ClonePointsRecordList(t.entity.PointsRecord)
+	list := tview.NewList().ShowSecondaryText(true)
+
+	var rebuild func()
+	rebuild = func() {
+		list.Clear()
+		for _, rec := range current {
+			list.AddItem(rec.When.String()+"  "+rec.Points.String(), rec.Reason, 0, nil)
+		}
+	}
+	list.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		current = append(current[:index], current[index+1:]...)
+		rebuild()
+	})
+	rebuild()
+
+	form := tview.NewForm().
+		AddButton(i18n.Text("Add Entry"), func() {
+			current = append([]*gurps.PointsRecord{{When: jio.Now(), Points: fxp.Int(0)}}, current...)
+			rebuild()
+		}).
+		AddButton(i18n.Text("Apply"), func() {
+			t.entity.SetPointsRecord(current)
+			t.refresh()
+			pages.RemovePage("points")
+			t.app.SetFocus(t.weapons)
+		}).
+		AddButton(i18n.Text("Cancel"), func() {
+			pages.RemovePage("points")
+			t.app.SetFocus(t.weapons)
+		})
+
+	modal := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, true).
+		AddItem(form, 3, 0, false)
+	modal.SetBorder(true).SetTitle(i18n.Text("Points Record"))
+
+	pages.AddPage("points", modal, true, true)
+	t.app.SetFocus(list)
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/richardwilkes/gcs/v5/model/gurps"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/rivo/tview"
+)
+
+// sheetTab holds the widgets for a single loaded sheet.
+type sheetTab struct {
+	app         *tview.Application
+	pages       *tview.Pages
+	path        string
+	title       string
+	entity      *gurps.Entity
+	root        *tview.Flex
+	attributes  *tview.TextView
+	points      *tview.TextView
+	encumbrance *tview.TextView
+	browse      *browsePanel
+	weapons     *tview.Table
+	allWeapons  []*gurps.Weapon
+}
+
+func newSheetTab(app *tview.Application, pages *tview.Pages, path string, entity *gurps.Entity) *sheetTab {
+	t := &sheetTab{
+		app:         app,
+		pages:       pages,
+		path:        path,
+		title:       entity.Profile.Name,
+		entity:      entity,
+		attributes:  tview.NewTextView().SetDynamicColors(true),
+		points:      tview.NewTextView().SetDynamicColors(true),
+		encumbrance: tview.NewTextView().SetDynamicColors(true),
+		browse:      newBrowsePanel(),
+		weapons:     tview.NewTable().SetBorders(false),
+	}
+	t.attributes.SetBorder(true).SetTitle(i18n.Text("Attributes"))
+	t.points.SetBorder(true).SetTitle(i18n.Text("Points"))
+	t.encumbrance.SetBorder(true).SetTitle(i18n.Text("Encumbrance"))
+	t.weapons.SetBorder(true).
+		SetTitle(i18n.Text("Weapons (f: fire, r: reload, p: points, Ctrl-B: browse, Ctrl-S: save)"))
+	t.weapons.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyCtrlS:
+			if err := t.save(); err != nil {
+				errs.Log(err)
+			}
+			return nil
+		case event.Key() == tcell.KeyCtrlB:
+			t.browse.next()
+			return nil
+		case event.Rune() == 'p':
+			t.showPointsForm(t.pages)
+			return nil
+		case event.Rune() == 'f':
+			t.fireSelected()
+			return nil
+		case event.Rune() == 'r':
+			t.reloadSelected()
+			return nil
+		default:
+			return event
+		}
+	})
+
+	top := tview.NewFlex().
+		AddItem(t.attributes, 0, 1, false).
+		AddItem(t.points, 0, 1, false).
+		AddItem(t.encumbrance, 0, 1, false).
+		AddItem(t.browse, 0, 2, false)
+	t.root = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(top, 0, 1, false).
+		AddItem(t.weapons, 0, 2, true)
+
+	t.refresh()
+	return t
+}
+
+// refresh repopulates the panels from the current entity state.
+func (t *sheetTab) refresh() {
+	t.attributes.Clear()
+	for _, attr := range t.entity.Attributes.List(false) {
+		fmt.Fprintf(t.attributes, "%s: %s\n", attr.AttributeDef().Name, attr.Maximum().String())
+	}
+
+	t.points.Clear()
+	for _, rec := range t.entity.PointsRecord {
+		fmt.Fprintf(t.points, "%s  %s  %s\n", rec.When.String(), rec.Points.String(), rec.Reason)
+	}
+
+	t.encumbrance.Clear()
+	level := t.entity.EncumbranceLevel(false)
+	fmt.Fprintf(t.encumbrance, "%s\n", level.String())
+	if movePenalty := t.entity.ReadiedWeaponMovePenalty(); movePenalty > 0 {
+		fmt.Fprintf(t.encumbrance, i18n.Text("Weapons: -%s Move")+"\n", movePenalty.String())
+	}
+
+	t.weapons.Clear()
+	headers := []string{
+		i18n.Text("Weapon"), i18n.Text("SL"), i18n.Text("Damage"), i18n.Text("Parry"), i18n.Text("Block"),
+		i18n.Text("Acc"), i18n.Text("Range"), i18n.Text("Shots"),
+	}
+	for col, header := range headers {
+		t.weapons.SetCell(0, col, tview.NewTableCell(header).SetSelectable(false))
+	}
+	declared := t.entity.Weapons()
+	combined := append([]*gurps.Weapon{}, declared...)
+	for _, eqp := range t.entity.CarriedEquipment {
+		hasOwn := false
+		for _, w := range declared {
+			if owner, ok := w.Owner.(*gurps.Equipment); ok && owner == eqp {
+				hasOwn = true
+				break
+			}
+		}
+		if !hasOwn {
+			combined = append(combined, gurps.CollectEquipmentWeapons(eqp, nil)...)
+		}
+	}
+	melee, ranged := gurps.SeparateWeapons(combined)
+	t.allWeapons = append(append([]*gurps.Weapon{}, melee...), ranged...)
+	for row, w := range t.allWeapons {
+		t.weapons.SetCell(row+1, 0, tview.NewTableCell(w.String()))
+		t.weapons.SetCell(row+1, 1, tview.NewTableCell(w.SkillLevel(nil).String()))
+		t.weapons.SetCell(row+1, 2, tview.NewTableCell(w.Damage.ResolvedDamage(nil)))
+		if w.Type == gurps.MeleeWeaponType {
+			t.weapons.SetCell(row+1, 3, tview.NewTableCell(w.ResolvedParry(nil)))
+			t.weapons.SetCell(row+1, 4, tview.NewTableCell(w.ResolvedBlock(nil)))
+			continue
+		}
+		weaponAcc, scopeAcc := w.ResolvedAccuracy(nil)
+		acc := weaponAcc.String()
+		if scopeAcc != 0 {
+			acc = gurps.WeaponAccuracy{Base: weaponAcc, Scope: scopeAcc}.String(w)
+		}
+		t.weapons.SetCell(row+1, 5, tview.NewTableCell(acc))
+		t.weapons.SetCell(row+1, 6, tview.NewTableCell(w.ResolvedRange()))
+		t.weapons.SetCell(row+1, 7, tview.NewTableCell(w.CombinedShots(nil)))
+	}
+
+	t.browse.setCategories([]browseCategory{
+		{Label: i18n.Text("Advantages"), Lines: stringerLines(t.entity.Traits)},
+		{Label: i18n.Text("Skills"), Lines: stringerLines(t.entity.Skills)},
+		{Label: i18n.Text("Spells"), Lines: stringerLines(t.entity.Spells)},
+		{Label: i18n.Text("Equipment"), Lines: stringerLines(t.entity.CarriedEquipment)},
+	})
+}
+
+// fireSelected fires one shot from the currently selected ranged weapon, if
+// any, then refreshes the table.
+func (t *sheetTab) fireSelected() {
+	row, _ := t.weapons.GetSelection()
+	if w := t.selectedRangedWeapon(row); w != nil {
+		w.Fire(1)
+		t.refresh()
+	}
+}
+
+// reloadSelected reloads the currently selected ranged weapon with whatever
+// ammo type it already had loaded, then refreshes the table.
+func (t *sheetTab) reloadSelected() {
+	row, _ := t.weapons.GetSelection()
+	if w := t.selectedRangedWeapon(row); w != nil {
+		ammoType := w.LoadedAmmoType
+		if ammoType == "" {
+			ammoType = i18n.Text("standard")
+		}
+		w.Reload(ammoType)
+		t.refresh()
+	}
+}
+
+func (t *sheetTab) selectedRangedWeapon(row int) *gurps.Weapon {
+	index := row - 1
+	if index < 0 || index >= len(t.allWeapons) {
+		return nil
+	}
+	if w := t.allWeapons[index]; w.Type == gurps.RangedWeaponType {
+		return w
+	}
+	return nil
+}
+
+// save writes the entity back through the existing save path.
+func (t *sheetTab) save() error {
+	return t.entity.Save(t.path)
+}
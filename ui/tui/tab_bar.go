@@ -0,0 +1,60 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package tui
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+)
+
+// tabBar renders the list of open sheets and switches the active page in
+// response to Tab/Shift+Tab.
+type tabBar struct {
+	*tview.TextView
+	tabs    []*sheetTab
+	pages   *tview.Pages
+	current int
+}
+
+func newTabBar(tabs []*sheetTab, pages *tview.Pages) *tabBar {
+	bar := &tabBar{
+		TextView: tview.NewTextView().SetDynamicColors(true),
+		tabs:     tabs,
+		pages:    pages,
+	}
+	bar.redraw()
+	return bar
+}
+
+func (b *tabBar) redraw() {
+	b.Clear()
+	for i, t := range b.tabs {
+		if i == b.current {
+			fmt.Fprintf(b, "[black:white] %s [-:-]", t.title)
+		} else {
+			fmt.Fprintf(b, " %s ", t.title)
+		}
+	}
+}
+
+func (b *tabBar) next() {
+	b.show((b.current + 1) % len(b.tabs))
+}
+
+func (b *tabBar) previous() {
+	b.show((b.current - 1 + len(b.tabs)) % len(b.tabs))
+}
+
+func (b *tabBar) show(index int) {
+	b.current = index
+	b.pages.SwitchToPage(b.tabs[index].path)
+	b.redraw()
+}
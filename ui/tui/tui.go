@@ -0,0 +1,66 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+// Package tui provides a terminal (non-Unison) interface for viewing and
+// lightly editing character sheets. It is independent of the Unison-based
+// ui package so that headless, SSH-only hosts can run GCS without X11.
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/richardwilkes/gcs/v5/model/gurps"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/rivo/tview"
+)
+
+// Run starts the terminal UI, loading the given sheet files, one per tab. It
+// blocks until the user quits.
+func Run(files []string) error {
+	app := tview.NewApplication()
+	pages := tview.NewPages()
+	tabs := make([]*sheetTab, 0, len(files))
+	for _, f := range files {
+		entity, err := gurps.NewEntityFromFile(f)
+		if err != nil {
+			return errs.NewWithCause(fmt.Sprintf(i18n.Text("unable to load %s"), f), err)
+		}
+		tab := newSheetTab(app, pages, f, entity)
+		tabs = append(tabs, tab)
+		pages.AddPage(f, tab.root, true, len(tabs) == 1)
+	}
+	if len(tabs) == 0 {
+		return errs.New(i18n.Text("no files to display"))
+	}
+
+	tabBar := newTabBar(tabs, pages)
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tabBar, 1, 0, false).
+		AddItem(pages, 0, 1, true)
+
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyCtrlC:
+			app.Stop()
+			return nil
+		case event.Key() == tcell.KeyTab:
+			tabBar.next()
+			return nil
+		case event.Key() == tcell.KeyBacktab:
+			tabBar.previous()
+			return nil
+		default:
+			return event
+		}
+	})
+
+	return app.SetRoot(root, true).SetFocus(pages).Run()
+}